@@ -10,12 +10,15 @@ require (
 	github.com/Masterminds/sprig v2.20.0+incompatible // indirect
 	github.com/banzaicloud/istio-operator v0.0.0-20190821151858-a47cd7d9bc7a
 	github.com/banzaicloud/k8s-objectmatcher v1.0.1
-	github.com/machinebox/graphql v0.2.2
-	github.com/matryer/is v1.2.0 // indirect
+	github.com/evanphx/json-patch v4.5.0+incompatible
 	github.com/mattn/go-isatty v0.0.8
 	github.com/pkg/browser v0.0.0-20180916011732-0a3d74bf9ce4
+	github.com/pmezard/go-difflib v1.0.0
+	github.com/prometheus/client_golang v1.0.0
+	github.com/prometheus/common v0.6.0
 	github.com/sirupsen/logrus v1.4.2
 	github.com/spf13/cobra v0.0.5
+	github.com/spf13/pflag v1.0.3
 	github.com/spf13/viper v1.4.0
 	github.com/ttacon/chalk v0.0.0-20160626202418-22c06c80ed31
 	github.com/waynz0r/grafterm v0.2.1-0.20190814214739-b7722452f1e4
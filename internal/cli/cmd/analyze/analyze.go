@@ -0,0 +1,203 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyze
+
+import (
+	"context"
+	"strings"
+
+	"emperror.dev/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/output"
+)
+
+type analyzeCommand struct{}
+
+type analyzeOptions struct {
+	target string
+
+	scope scope
+}
+
+func newAnalyzeOptions() *analyzeOptions {
+	return &analyzeOptions{}
+}
+
+func NewAnalyzeCommand(cli cli.CLI) *cobra.Command {
+	c := &analyzeCommand{}
+	options := newAnalyzeOptions()
+
+	cmd := &cobra.Command{
+		Use:   "analyze [namespace[/servicename]]",
+		Short: "Analyze the routing configuration of the mesh",
+		Long: `Analyze the routing configuration of the mesh.
+
+Services, virtual services, destination rules and gateways are checked for common problems,
+like routes to undefined subsets, weights which do not add up to 100 or conflicting virtual services.
+The findings can be limited to a namespace or a single service, in which case only the
+resources of that namespace are loaded and references to other namespaces are not checked.
+The command exits with a non-zero status if any error is found.`,
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				options.target = args[0]
+			}
+
+			if strings.Contains(options.target, "/") {
+				serviceName, err := common.ParseServiceID(options.target)
+				if err != nil {
+					return err
+				}
+				options.scope = scope{
+					namespace: serviceName.Namespace,
+					name:      serviceName.Name,
+				}
+			} else {
+				options.scope = scope{
+					namespace: options.target,
+				}
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	return cmd
+}
+
+func (c *analyzeCommand) run(cli cli.CLI, options *analyzeOptions) error {
+	var err error
+
+	config, err := c.loadMeshConfig(cli, options.scope)
+	if err != nil {
+		return err
+	}
+
+	findings := analyze(config, options.scope)
+	if len(findings) == 0 {
+		log.Info("no problems found")
+		return nil
+	}
+
+	ctx := &output.Context{
+		Out:     cli.Out(),
+		Color:   cli.Color(),
+		Format:  cli.OutputFormat(),
+		Fields:  []string{"Severity", "Kind", "Object", "Message"},
+		Headers: []string{"Severity", "Kind", "Object", "Message"},
+	}
+
+	err = output.Output(ctx, findings)
+	if err != nil {
+		return errors.WrapIf(err, "could not produce output")
+	}
+
+	errorCount := 0
+	for _, finding := range findings {
+		if finding.Severity == SeverityError {
+			errorCount++
+		}
+	}
+	if errorCount > 0 {
+		return errors.Errorf("%d error(s) found in the routing configuration", errorCount)
+	}
+
+	return nil
+}
+
+// loadMeshConfig loads the routing related resources of the namespace of the scope, or of every namespace if the
+// scope is empty, after checking that the target of the scope exists
+func (c *analyzeCommand) loadMeshConfig(cli cli.CLI, scope scope) (*meshConfig, error) {
+	var err error
+
+	cl, err := cli.GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case scope.name != "":
+		_, err = common.GetServiceByName(cli, types.NamespacedName{Name: scope.name, Namespace: scope.namespace})
+		if err != nil {
+			if k8serrors.IsNotFound(errors.Cause(err)) {
+				return nil, err
+			}
+			return nil, errors.WrapIf(err, "could not get service")
+		}
+	case scope.namespace != "":
+		var namespace corev1.Namespace
+		err = cl.Get(context.Background(), types.NamespacedName{Name: scope.namespace}, &namespace)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				return nil, errors.WithStack(err)
+			}
+			return nil, errors.WrapIf(err, "could not get namespace")
+		}
+	}
+
+	listOpts := make([]client.ListOptionFunc, 0)
+	if scope.namespace != "" {
+		listOpts = append(listOpts, client.InNamespace(scope.namespace))
+	}
+
+	var services corev1.ServiceList
+	err = cl.List(context.Background(), &services, listOpts...)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list services")
+	}
+
+	var pods corev1.PodList
+	err = cl.List(context.Background(), &pods, listOpts...)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list pods")
+	}
+
+	var vservices v1alpha3.VirtualServiceList
+	err = cl.List(context.Background(), &vservices, listOpts...)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list virtual services")
+	}
+
+	var drules v1alpha3.DestinationRuleList
+	err = cl.List(context.Background(), &drules, listOpts...)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list destination rules")
+	}
+
+	var gateways v1alpha3.GatewayList
+	err = cl.List(context.Background(), &gateways, listOpts...)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list gateways")
+	}
+
+	return &meshConfig{
+		namespace: scope.namespace,
+		services:  services.Items,
+		pods:      pods.Items,
+		vservices: vservices.Items,
+		drules:    drules.Items,
+		gateways:  gateways.Items,
+	}, nil
+}
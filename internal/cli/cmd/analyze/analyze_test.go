@@ -0,0 +1,68 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyze
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli/clitest"
+)
+
+func TestAnalyzeCommandTargets(t *testing.T) {
+	k8sClient := clitest.NewK8sClient(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "demo"}},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "movies"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 8080}}},
+		},
+	)
+
+	tests := map[string]struct {
+		args     []string
+		notFound bool
+	}{
+		"existing namespace": {args: []string{"demo"}},
+		"existing service":   {args: []string{"demo/movies"}},
+		"unknown namespace":  {args: []string{"other"}, notFound: true},
+		"unknown service":    {args: []string{"demo/books"}, notFound: true},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+
+		t.Run(name, func(t *testing.T) {
+			cmd := NewAnalyzeCommand(clitest.NewFakeCLI(k8sClient, nil))
+			cmd.SetArgs(test.args)
+			cmd.SetOutput(ioutil.Discard)
+
+			err := cmd.Execute()
+			if test.notFound {
+				if !k8serrors.IsNotFound(errors.Cause(err)) {
+					t.Fatalf("expected not found error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyze
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+
+	meshGateway = "mesh"
+)
+
+var protocolPrefixes = []string{"http", "http2", "https", "grpc", "grpc-web", "tcp", "tls", "udp", "mongo", "mysql", "redis"}
+
+// Finding is a problem found in the routing configuration of the mesh
+type Finding struct {
+	Severity string `json:"severity"`
+	Kind     string `json:"kind"`
+	Object   string `json:"object"`
+	Message  string `json:"message"`
+}
+
+// meshConfig holds the resources which take part in the routing of the mesh, they are loaded from
+// a single namespace if namespace is set, or from every namespace otherwise
+type meshConfig struct {
+	namespace string
+	services  []corev1.Service
+	pods      []corev1.Pod
+	vservices []v1alpha3.VirtualService
+	drules    []v1alpha3.DestinationRule
+	gateways  []v1alpha3.Gateway
+}
+
+// scope selects the resources to report findings for, an empty scope selects everything
+type scope struct {
+	namespace string
+	name      string
+}
+
+func (s scope) includes(namespace string, hosts ...string) bool {
+	if s.namespace == "" {
+		return true
+	}
+
+	if s.name == "" {
+		return namespace == s.namespace
+	}
+
+	service := common.GetFQDN(s.name, s.namespace)
+	for _, host := range hosts {
+		if common.GetFQDN(host, namespace) == service {
+			return true
+		}
+	}
+
+	return false
+}
+
+type analyzer struct {
+	config   *meshConfig
+	scope    scope
+	findings []Finding
+}
+
+func analyze(config *meshConfig, scope scope) []Finding {
+	a := &analyzer{
+		config:   config,
+		scope:    scope,
+		findings: make([]Finding, 0),
+	}
+
+	a.checkServices()
+	a.checkVirtualServices()
+	a.checkDestinationRules()
+
+	sort.SliceStable(a.findings, func(i, j int) bool {
+		return severityOrder(a.findings[i].Severity) < severityOrder(a.findings[j].Severity)
+	})
+
+	return a.findings
+}
+
+func (a *analyzer) report(severity, kind, namespace, name, format string, args ...interface{}) {
+	a.findings = append(a.findings, Finding{
+		Severity: severity,
+		Kind:     kind,
+		Object:   namespace + "/" + name,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (a *analyzer) checkServices() {
+	for _, service := range a.config.services {
+		if !a.scope.includes(service.Namespace, service.Name) {
+			continue
+		}
+
+		for _, port := range service.Spec.Ports {
+			if port.Name == "" {
+				a.report(SeverityWarning, "Service", service.Namespace, service.Name,
+					"port %d is unnamed, its traffic is handled as plain TCP", port.Port)
+				continue
+			}
+			if !hasProtocolPrefix(port.Name) {
+				a.report(SeverityInfo, "Service", service.Namespace, service.Name,
+					"name of port %d (%s) does not start with a protocol, its traffic is handled as plain TCP", port.Port, port.Name)
+			}
+		}
+	}
+}
+
+func (a *analyzer) checkVirtualServices() {
+	hosts := make(map[string][]string)
+
+	for _, vservice := range a.config.vservices {
+		if !a.scope.includes(vservice.Namespace, vservice.Spec.Hosts...) {
+			continue
+		}
+
+		report := func(severity, format string, args ...interface{}) {
+			a.report(severity, "VirtualService", vservice.Namespace, vservice.Name, format, args...)
+		}
+
+		for _, gateway := range vservice.Spec.Gateways {
+			if gateway != meshGateway && a.isGatewayLoaded(gateway, vservice.Namespace) && !a.gatewayExists(gateway, vservice.Namespace) {
+				report(SeverityError, "gateway %s does not exist", gateway)
+			}
+		}
+
+		if len(vservice.Spec.Gateways) == 0 || contains(vservice.Spec.Gateways, meshGateway) {
+			for _, host := range vservice.Spec.Hosts {
+				fqdn := common.GetFQDN(host, vservice.Namespace)
+				hosts[fqdn] = append(hosts[fqdn], vservice.Namespace+"/"+vservice.Name)
+			}
+		}
+
+		for i, route := range vservice.Spec.HTTP {
+			if len(route.Route) > 1 {
+				sum := 0
+				for _, r := range route.Route {
+					sum += r.Weight
+				}
+				if sum != 100 {
+					report(SeverityError, "weights of http route #%d sum up to %d instead of 100", i+1, sum)
+				}
+			}
+
+			for _, r := range route.Route {
+				a.checkDestination(vservice, r.Destination, fmt.Sprintf("http route #%d", i+1))
+			}
+			if route.Mirror != nil {
+				a.checkDestination(vservice, *route.Mirror, fmt.Sprintf("mirror of http route #%d", i+1))
+			}
+		}
+
+		for i, route := range vservice.Spec.TCP {
+			for _, r := range route.Route {
+				a.checkDestination(vservice, r.Destination, fmt.Sprintf("tcp route #%d", i+1))
+			}
+		}
+
+		for i, route := range vservice.Spec.TLS {
+			for _, r := range route.Route {
+				a.checkDestination(vservice, r.Destination, fmt.Sprintf("tls route #%d", i+1))
+			}
+		}
+	}
+
+	fqdns := make([]string, 0, len(hosts))
+	for fqdn := range hosts {
+		fqdns = append(fqdns, fqdn)
+	}
+	sort.Strings(fqdns)
+
+	for _, fqdn := range fqdns {
+		vservices := hosts[fqdn]
+		if len(vservices) < 2 {
+			continue
+		}
+		for _, vservice := range vservices {
+			parts := strings.SplitN(vservice, "/", 2)
+			a.report(SeverityError, "VirtualService", parts[0], parts[1],
+				"host %s is also routed by %s, only one of them takes effect", fqdn, strings.Join(exclude(vservices, vservice), ", "))
+		}
+	}
+}
+
+func (a *analyzer) checkDestination(vservice v1alpha3.VirtualService, destination v1alpha3.Destination, route string) {
+	if destination.Subset == "" {
+		return
+	}
+
+	host := common.GetFQDN(destination.Host, vservice.Namespace)
+	drule := a.getDestinationRule(host)
+	if drule == nil {
+		// the destination rule may be defined in the namespace of a service which is not loaded
+		if serviceName, ok := common.GetServiceNameFromHost(destination.Host, vservice.Namespace); ok && !a.isLoaded(serviceName.Namespace) {
+			return
+		}
+		a.report(SeverityError, "VirtualService", vservice.Namespace, vservice.Name,
+			"%s references subset %s of %s, but there is no destination rule for the host", route, destination.Subset, destination.Host)
+		return
+	}
+
+	for _, subset := range drule.Spec.Subsets {
+		if subset.Name == destination.Subset {
+			return
+		}
+	}
+
+	a.report(SeverityError, "VirtualService", vservice.Namespace, vservice.Name,
+		"%s references subset %s of %s, which is not defined in destination rule %s/%s", route, destination.Subset, destination.Host, drule.Namespace, drule.Name)
+}
+
+func (a *analyzer) checkDestinationRules() {
+	for _, drule := range a.config.drules {
+		if !a.scope.includes(drule.Namespace, drule.Spec.Host) {
+			continue
+		}
+
+		service := a.getService(common.GetFQDN(drule.Spec.Host, drule.Namespace))
+		if service == nil || len(service.Spec.Selector) == 0 {
+			continue
+		}
+
+		for _, subset := range drule.Spec.Subsets {
+			selector := labels.SelectorFromSet(labels.Merge(service.Spec.Selector, subset.Labels))
+			if a.countPods(service.Namespace, selector) == 0 {
+				a.report(SeverityWarning, "DestinationRule", drule.Namespace, drule.Name,
+					"subset %s does not match any pods of service %s/%s", subset.Name, service.Namespace, service.Name)
+			}
+		}
+	}
+}
+
+// isLoaded returns whether the resources of a namespace are loaded
+func (a *analyzer) isLoaded(namespace string) bool {
+	return a.config.namespace == "" || a.config.namespace == namespace
+}
+
+func (a *analyzer) isGatewayLoaded(gateway, namespace string) bool {
+	if parts := strings.SplitN(gateway, "/", 2); len(parts) == 2 {
+		namespace = parts[0]
+	}
+
+	return a.isLoaded(namespace)
+}
+
+func (a *analyzer) gatewayExists(gateway, namespace string) bool {
+	name := gateway
+	if parts := strings.SplitN(gateway, "/", 2); len(parts) == 2 {
+		namespace, name = parts[0], parts[1]
+	}
+
+	for _, g := range a.config.gateways {
+		if g.Namespace == namespace && g.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *analyzer) getDestinationRule(host string) *v1alpha3.DestinationRule {
+	for i, drule := range a.config.drules {
+		if common.GetFQDN(drule.Spec.Host, drule.Namespace) == host {
+			return &a.config.drules[i]
+		}
+	}
+
+	return nil
+}
+
+func (a *analyzer) getService(host string) *corev1.Service {
+	for i, service := range a.config.services {
+		if common.GetFQDN(service.Name, service.Namespace) == host {
+			return &a.config.services[i]
+		}
+	}
+
+	return nil
+}
+
+func (a *analyzer) countPods(namespace string, selector labels.Selector) int {
+	count := 0
+	for _, pod := range a.config.pods {
+		if pod.Namespace == namespace && selector.Matches(labels.Set(pod.Labels)) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func hasProtocolPrefix(name string) bool {
+	for _, prefix := range protocolPrefixes {
+		if name == prefix || strings.HasPrefix(name, prefix+"-") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func severityOrder(severity string) int {
+	switch severity {
+	case SeverityError:
+		return 0
+	case SeverityWarning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func exclude(list []string, s string) []string {
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,164 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyze
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+)
+
+func TestAnalyze(t *testing.T) {
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: "demo", Name: name}
+	}
+	route := func(weights map[string]int) v1alpha3.HTTPRoute {
+		r := v1alpha3.HTTPRoute{}
+		for _, subset := range []string{"v1", "v2", "v3"} {
+			if weight, ok := weights[subset]; ok {
+				r.Route = append(r.Route, v1alpha3.HTTPRouteDestination{
+					Destination: v1alpha3.Destination{Host: "movies", Subset: subset},
+					Weight:      weight,
+				})
+			}
+		}
+		return r
+	}
+
+	config := &meshConfig{
+		services: []corev1.Service{
+			{
+				ObjectMeta: meta("movies"),
+				Spec: corev1.ServiceSpec{
+					Selector: map[string]string{"app": "movies"},
+					Ports:    []corev1.ServicePort{{Name: "http", Port: 8080}, {Name: "metrics", Port: 9090}},
+				},
+			},
+			{
+				ObjectMeta: meta("bookings"),
+				Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 8080}}},
+			},
+		},
+		pods: []corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "movies-v1", Labels: map[string]string{"app": "movies", "version": "v1"}}},
+		},
+		vservices: []v1alpha3.VirtualService{
+			{
+				ObjectMeta: meta("movies"),
+				Spec: v1alpha3.VirtualServiceSpec{
+					Hosts:    []string{"movies"},
+					Gateways: []string{"mesh", "missing"},
+					HTTP:     []v1alpha3.HTTPRoute{route(map[string]int{"v1": 50, "v2": 40}), route(map[string]int{"v3": 100})},
+				},
+			},
+			{
+				ObjectMeta: meta("movies-canary"),
+				Spec:       v1alpha3.VirtualServiceSpec{Hosts: []string{"movies.demo.svc.cluster.local"}},
+			},
+		},
+		drules: []v1alpha3.DestinationRule{
+			{
+				ObjectMeta: meta("movies"),
+				Spec: v1alpha3.DestinationRuleSpec{
+					Host: "movies",
+					Subsets: []v1alpha3.Subset{
+						{Name: "v1", Labels: map[string]string{"version": "v1"}},
+						{Name: "v2", Labels: map[string]string{"version": "v2"}},
+					},
+				},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		scope    scope
+		expected []string
+	}{
+		"whole mesh": {
+			expected: []string{
+				"error VirtualService demo/movies: gateway missing does not exist",
+				"error VirtualService demo/movies: weights of http route #1 sum up to 90 instead of 100",
+				"error VirtualService demo/movies: http route #2 references subset v3 of movies, which is not defined in destination rule demo/movies",
+				"error VirtualService demo/movies: host movies.demo.svc.cluster.local is also routed by demo/movies-canary, only one of them takes effect",
+				"error VirtualService demo/movies-canary: host movies.demo.svc.cluster.local is also routed by demo/movies, only one of them takes effect",
+				"warning Service demo/bookings: port 8080 is unnamed, its traffic is handled as plain TCP",
+				"warning DestinationRule demo/movies: subset v2 does not match any pods of service demo/movies",
+				"info Service demo/movies: name of port 9090 (metrics) does not start with a protocol, its traffic is handled as plain TCP",
+			},
+		},
+		"single service": {
+			scope: scope{namespace: "demo", name: "bookings"},
+			expected: []string{
+				"warning Service demo/bookings: port 8080 is unnamed, its traffic is handled as plain TCP",
+			},
+		},
+		"other namespace": {
+			scope:    scope{namespace: "default"},
+			expected: []string{},
+		},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+
+		t.Run(name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, f := range analyze(config, test.scope) {
+				got = append(got, f.Severity+" "+f.Kind+" "+f.Object+": "+f.Message)
+			}
+
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("unexpected findings\ngot : %q\nwant: %q", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestAnalyzeNamespaceDoesNotCheckOtherNamespaces(t *testing.T) {
+	config := &meshConfig{
+		namespace: "demo",
+		vservices: []v1alpha3.VirtualService{
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "movies"},
+				Spec: v1alpha3.VirtualServiceSpec{
+					Hosts:    []string{"movies"},
+					Gateways: []string{"mesh", "istio-system/ingress", "missing"},
+					HTTP: []v1alpha3.HTTPRoute{{
+						Route: []v1alpha3.HTTPRouteDestination{
+							{Destination: v1alpha3.Destination{Host: "bookings.other.svc.cluster.local", Subset: "v1"}},
+						},
+						Mirror: &v1alpha3.Destination{Host: "movies", Subset: "v2"},
+					}},
+				},
+			},
+		},
+	}
+
+	got := make([]string, 0)
+	for _, f := range analyze(config, scope{namespace: "demo"}) {
+		got = append(got, f.Severity+" "+f.Kind+" "+f.Object+": "+f.Message)
+	}
+
+	expected := []string{
+		"error VirtualService demo/movies: gateway missing does not exist",
+		"error VirtualService demo/movies: mirror of http route #1 references subset v2 of movies, but there is no destination rule for the host",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected findings\ngot : %q\nwant: %q", got, expected)
+	}
+}
@@ -36,6 +36,8 @@ import (
 )
 
 const (
+	componentName = "canary"
+
 	istioNotFoundErrorTemplate = `Unable to install Backyards: %s
 
 An existing Istio installation is required. You can install it with:
@@ -53,13 +55,18 @@ type InstallOptions struct {
 	canaryOperatorNamespace string
 	istioNamespace          string
 	prometheusURL           string
+	values                  helm.ValueOverrides
 
 	DumpResources bool
+	Prune         bool
+	DryRun        k8s.DryRunStrategy
 }
 
 // NewInstallOptions get InstallOptions
 func NewInstallOptions() *InstallOptions {
-	return &InstallOptions{}
+	return &InstallOptions{
+		Prune: true,
+	}
 }
 
 // NewInstallCommand get installCommand
@@ -96,6 +103,10 @@ It can only dump the applicable resources with the '--dump-resources' option.
 	cmd.Flags().StringVar(&options.prometheusURL, "prometheus-url", "http://backyards-prometheus.backyards-system", "Prometheus URL for metrics")
 
 	cmd.Flags().BoolVarP(&options.DumpResources, "dump-resources", "d", options.DumpResources, "Dump resources to stdout instead of applying them")
+	cmd.Flags().BoolVar(&options.Prune, "prune", options.Prune, "Delete the previously applied resources of the release which are not rendered any more")
+	options.DryRun.AddFlag(cmd.Flags())
+
+	options.values.AddFlags(cmd.Flags())
 
 	return cmd
 }
@@ -107,18 +118,33 @@ func (c *installCommand) run(cli cli.CLI, options *InstallOptions) error {
 		return nil
 	}
 
-	objects, err := getCanaryOperatorObjects(options.releaseName, options.canaryOperatorNamespace, options.prometheusURL)
+	objects, rawValues, err := getCanaryOperatorObjects(options.releaseName, options.canaryOperatorNamespace, options.prometheusURL, options.values)
 	if err != nil {
 		return err
 	}
 	objects.Sort(helm.InstallObjectOrder())
 
+	labels := k8s.OwnershipLabels(options.releaseName, componentName, options.canaryOperatorNamespace)
+	k8s.LabelResources(objects, labels)
+
 	if !options.DumpResources {
 		client, err := cli.GetK8sClient()
 		if err != nil {
 			return err
 		}
 
+		if options.DryRun.Enabled() {
+			var pruned object.K8sObjects
+			if options.Prune {
+				pruned, err = k8s.PruneResources(client, objects, k8s.GroupVersionKinds(objects), labels, true)
+				if err != nil {
+					return err
+				}
+			}
+
+			return util.DryRun(cli, client, options.DryRun, objects, pruned)
+		}
+
 		err = k8s.ApplyResources(client, objects)
 		if err != nil {
 			return err
@@ -133,6 +159,18 @@ func (c *installCommand) run(cli cli.CLI, options *InstallOptions) error {
 		if err != nil {
 			return err
 		}
+
+		if options.Prune {
+			_, err = k8s.PruneResources(client, objects, k8s.GroupVersionKinds(objects), labels, false)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = util.RecordRelease(cli, client, options.releaseName, objects, rawValues, "")
+		if err != nil {
+			return err
+		}
 	} else {
 		yaml, err := objects.YAMLManifest()
 		if err != nil {
@@ -144,37 +182,39 @@ func (c *installCommand) run(cli cli.CLI, options *InstallOptions) error {
 	return nil
 }
 
-func getCanaryOperatorObjects(releaseName, canaryOperatorNamespace, prometheusURL string) (object.K8sObjects, error) {
+// getCanaryOperatorObjects renders the chart of the canary operator, the values it was rendered with are returned in
+// YAML format as well
+func getCanaryOperatorObjects(releaseName, canaryOperatorNamespace, prometheusURL string, overrides helm.ValueOverrides) (object.K8sObjects, string, error) {
 	var values Values
 
 	valuesYAML, err := helm.GetDefaultValues(canary_operator.Chart)
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not get helm default values")
+		return nil, "", errors.WrapIf(err, "could not get helm default values")
 	}
 
 	err = yaml.Unmarshal(valuesYAML, &values)
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not unmarshal yaml values")
+		return nil, "", errors.WrapIf(err, "could not unmarshal yaml values")
 	}
 
 	values.SetDefaults(releaseName, prometheusURL)
 
-	rawValues, err := yaml.Marshal(values)
+	rawValues, err := overrides.Merge(&values)
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not marshal yaml values")
+		return nil, "", err
 	}
 
-	objects, err := helm.Render(canary_operator.Chart, string(rawValues), helm.ReleaseOptions{
+	objects, err := helm.Render(canary_operator.Chart, rawValues, helm.ReleaseOptions{
 		Name:      "canary-operator",
 		IsInstall: true,
 		IsUpgrade: false,
 		Namespace: canaryOperatorNamespace,
 	}, "canary-operator")
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not render helm manifest objects")
+		return nil, "", errors.WrapIf(err, "could not render helm manifest objects")
 	}
 
-	return objects, nil
+	return objects, rawValues, nil
 }
 
 func (c *installCommand) validate(istioNamespace string) error {
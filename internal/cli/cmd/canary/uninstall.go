@@ -23,6 +23,7 @@ import (
 	"istio.io/operator/pkg/object"
 	"k8s.io/apimachinery/pkg/util/wait"
 
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/util"
 	"github.com/banzaicloud/backyards-cli/pkg/cli"
 	"github.com/banzaicloud/backyards-cli/pkg/helm"
 	"github.com/banzaicloud/backyards-cli/pkg/k8s"
@@ -37,6 +38,7 @@ type UninstallOptions struct {
 	canaryOperatorNamespace string
 
 	DumpResources bool
+	DryRun        k8s.DryRunStrategy
 }
 
 func NewUninstallOptions() *UninstallOptions {
@@ -75,22 +77,40 @@ The command can uninstall every component at once with the '--uninstall-everythi
 	cmd.Flags().StringVar(&options.canaryOperatorNamespace, "canary-namespace", "backyards-canary", "Namespace for the canary operator")
 
 	cmd.Flags().BoolVarP(&options.DumpResources, "dump-resources", "d", options.DumpResources, "Dump resources to stdout instead of applying them")
+	options.DryRun.AddFlag(cmd.Flags())
 
 	return cmd
 }
 
 func (c *uninstallCommand) run(cli cli.CLI, options *UninstallOptions) error {
-	objects, err := getCanaryOperatorObjects(options.releaseName, options.canaryOperatorNamespace, "")
+	client, err := cli.GetK8sClient()
+	if err != nil {
+		return err
+	}
+
+	// releases installed before the CLI kept records are removed by rendering the chart with the default values
+	objects, err := util.GetInstalledObjects(client, options.releaseName, func() (object.K8sObjects, error) {
+		objects, _, err := getCanaryOperatorObjects(options.releaseName, options.canaryOperatorNamespace, "", helm.ValueOverrides{})
+		return objects, err
+	})
 	if err != nil {
 		return err
 	}
 	objects.Sort(helm.UninstallObjectOrder())
 
 	if !options.DumpResources {
-		err := c.deleteResources(objects)
+		err := c.deleteResources(objects, options.DryRun)
 		if err != nil {
 			return errors.WrapIf(err, "could not delete k8s resources")
 		}
+
+		if !options.DryRun.Enabled() {
+			err = util.GetReleaseStore(client).Delete(options.releaseName)
+			if err != nil {
+				return errors.WrapIf(err, "could not delete release records")
+			}
+		}
+
 		return nil
 	}
 
@@ -103,12 +123,16 @@ func (c *uninstallCommand) run(cli cli.CLI, options *UninstallOptions) error {
 	return nil
 }
 
-func (c *uninstallCommand) deleteResources(objects object.K8sObjects) error {
+func (c *uninstallCommand) deleteResources(objects object.K8sObjects, dryRun k8s.DryRunStrategy) error {
 	client, err := c.cli.GetK8sClient()
 	if err != nil {
 		return err
 	}
 
+	if dryRun.Enabled() {
+		return util.DryRun(c.cli, client, dryRun, nil, objects)
+	}
+
 	err = k8s.DeleteResources(client, objects, k8s.WaitForResourceConditions(wait.Backoff{
 		Duration: time.Second * 5,
 		Factor:   1,
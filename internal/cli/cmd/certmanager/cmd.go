@@ -23,6 +23,7 @@ import (
 const (
 	CertManagerNamespace   = "cert-manager"
 	certManagerReleaseName = "cert-manager"
+	componentName          = "cert-manager"
 )
 
 func NewRootCmd(cli cli.CLI) *cobra.Command {
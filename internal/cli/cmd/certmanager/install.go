@@ -35,6 +35,7 @@ import (
 	"github.com/banzaicloud/backyards-cli/cmd/backyards/static/certmanager"
 	"github.com/banzaicloud/backyards-cli/cmd/backyards/static/certmanagercainjector"
 	"github.com/banzaicloud/backyards-cli/cmd/backyards/static/certmanagercrds"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/util"
 	"github.com/banzaicloud/backyards-cli/pkg/cli"
 	"github.com/banzaicloud/backyards-cli/pkg/helm"
 	"github.com/banzaicloud/backyards-cli/pkg/k8s"
@@ -46,10 +47,14 @@ type installCommand struct {
 
 type InstallOptions struct {
 	DumpResources bool
+	Prune         bool
+	DryRun        k8s.DryRunStrategy
 }
 
 func NewInstallOptions() *InstallOptions {
-	return &InstallOptions{}
+	return &InstallOptions{
+		Prune: true,
+	}
 }
 
 func NewInstallCommand(cli cli.CLI, options *InstallOptions) *cobra.Command {
@@ -77,6 +82,8 @@ It can only dump the applicable resources with the '--dump-resources' option.`,
 	}
 
 	cmd.Flags().BoolVarP(&options.DumpResources, "dump-resources", "d", options.DumpResources, "Dump resources to stdout instead of applying them")
+	cmd.Flags().BoolVar(&options.Prune, "prune", options.Prune, "Delete the previously applied resources of the release which are not rendered any more")
+	options.DryRun.AddFlag(cmd.Flags())
 
 	return cmd
 }
@@ -94,12 +101,27 @@ func (c *installCommand) run(cli cli.CLI, options *InstallOptions) error {
 	}
 	objects.Sort(helm.InstallObjectOrder())
 
+	labels := k8s.OwnershipLabels(certManagerReleaseName, componentName, CertManagerNamespace)
+	k8s.LabelResources(objects, labels)
+
 	if !options.DumpResources {
 		client, err := cli.GetK8sClient()
 		if err != nil {
 			return err
 		}
 
+		if options.DryRun.Enabled() {
+			var pruned object.K8sObjects
+			if options.Prune {
+				pruned, err = k8s.PruneResources(client, objects, k8s.GroupVersionKinds(objects), labels, true)
+				if err != nil {
+					return err
+				}
+			}
+
+			return util.DryRun(cli, client, options.DryRun, objects, pruned)
+		}
+
 		err = k8s.ApplyResources(client, objects)
 		if err != nil {
 			return err
@@ -114,6 +136,13 @@ func (c *installCommand) run(cli cli.CLI, options *InstallOptions) error {
 		if err != nil {
 			return err
 		}
+
+		if options.Prune {
+			_, err = k8s.PruneResources(client, objects, k8s.GroupVersionKinds(objects), labels, false)
+			if err != nil {
+				return err
+			}
+		}
 	} else {
 		yaml, err := objects.YAMLManifest()
 		if err != nil {
@@ -23,6 +23,7 @@ import (
 	"istio.io/operator/pkg/object"
 	"k8s.io/apimachinery/pkg/util/wait"
 
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/util"
 	"github.com/banzaicloud/backyards-cli/pkg/cli"
 	"github.com/banzaicloud/backyards-cli/pkg/helm"
 	"github.com/banzaicloud/backyards-cli/pkg/k8s"
@@ -34,6 +35,7 @@ type uninstallCommand struct {
 
 type UninstallOptions struct {
 	DumpResources bool
+	DryRun        k8s.DryRunStrategy
 }
 
 func NewUninstallOptions() *UninstallOptions {
@@ -67,6 +69,7 @@ It can only dump the removable resources with the '--dump-resources' option.`,
 	}
 
 	cmd.Flags().BoolVarP(&options.DumpResources, "dump-resources", "d", options.DumpResources, "Dump resources to stdout instead of applying them")
+	options.DryRun.AddFlag(cmd.Flags())
 
 	return cmd
 }
@@ -79,7 +82,7 @@ func (c *uninstallCommand) run(cli cli.CLI, options *UninstallOptions) error {
 	objects.Sort(helm.UninstallObjectOrder())
 
 	if !options.DumpResources {
-		err := c.deleteResources(objects)
+		err := c.deleteResources(objects, options.DryRun)
 		if err != nil {
 			return errors.WrapIf(err, "could not delete k8s resources")
 		}
@@ -95,12 +98,16 @@ func (c *uninstallCommand) run(cli cli.CLI, options *UninstallOptions) error {
 	return nil
 }
 
-func (c *uninstallCommand) deleteResources(objects object.K8sObjects) error {
+func (c *uninstallCommand) deleteResources(objects object.K8sObjects, dryRun k8s.DryRunStrategy) error {
 	client, err := c.cli.GetK8sClient()
 	if err != nil {
 		return err
 	}
 
+	if dryRun.Enabled() {
+		return util.DryRun(c.cli, client, dryRun, nil, objects)
+	}
+
 	err = k8s.DeleteResources(client, objects, k8s.WaitForResourceConditions(wait.Backoff{
 		Duration: time.Second * 5,
 		Factor:   1,
@@ -79,29 +79,24 @@ func (c *dashboardCommand) run(cli cli.CLI, options *DashboardOptions) error {
 	signal.Notify(signals, os.Interrupt)
 	defer signal.Stop(signals)
 
-	pf, err := cli.GetPortforwardForIGW(options.Port)
+	endpoint, err := cli.GetEndpoint(options.Port)
 	if err != nil {
-		return errors.WrapIf(err, "cloud not create port forwarder")
+		return errors.WrapIf(err, "could not get Backyards endpoint")
 	}
 
 	go func() {
 		<-signals
-		pf.Stop()
+		endpoint.Close()
 	}()
 
-	err = pf.Run()
-	if err != nil {
-		return errors.WrapIf(err, "could not run port forwarder")
-	}
-
-	url := pf.GetURL(options.URI)
+	url := endpoint.URLForPath(options.URI)
 	log.Infof("Backyards UI is available at %s", url)
 	err = browser.OpenURL(url)
 	if err != nil {
 		return err
 	}
 
-	pf.WaitForStop()
+	endpoint.Wait()
 
 	return nil
 }
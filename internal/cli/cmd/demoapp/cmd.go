@@ -19,6 +19,10 @@ import (
 	"github.com/banzaicloud/backyards-cli/pkg/cli"
 )
 
+const (
+	demoappReleaseName = "backyards-demo"
+)
+
 var (
 	backyardsDemoNamespace = "backyards-demo"
 )
@@ -36,6 +36,8 @@ import (
 )
 
 const (
+	componentName = "demoapp"
+
 	istioNotFoundErrorTemplate = `Unable to install Backyards: %s
 
 An existing Istio installation is required. You can install it with:
@@ -51,12 +53,17 @@ type installCommand struct {
 type InstallOptions struct {
 	namespace      string
 	istioNamespace string
+	values         helm.ValueOverrides
 
 	DumpResources bool
+	Prune         bool
+	DryRun        k8s.DryRunStrategy
 }
 
 func NewInstallOptions() *InstallOptions {
-	return &InstallOptions{}
+	return &InstallOptions{
+		Prune: true,
+	}
 }
 
 func NewInstallCommand(cli cli.CLI, options *InstallOptions) *cobra.Command {
@@ -92,6 +99,10 @@ It can only dump the applicable resources with the '--dump-resources' option.`,
 	cmd.Flags().StringVar(&options.istioNamespace, "istio-namespace", "istio-system", "Namespace of Istio sidecar injector")
 
 	cmd.Flags().BoolVarP(&options.DumpResources, "dump-resources", "d", options.DumpResources, "Dump resources to stdout instead of applying them")
+	cmd.Flags().BoolVar(&options.Prune, "prune", options.Prune, "Delete the previously applied resources of the release which are not rendered any more")
+	options.DryRun.AddFlag(cmd.Flags())
+
+	options.values.AddFlags(cmd.Flags())
 
 	return cmd
 }
@@ -103,18 +114,33 @@ func (c *installCommand) run(cli cli.CLI, options *InstallOptions) error {
 		return nil
 	}
 
-	objects, err := getBackyardsDemoObjects(options.namespace)
+	objects, rawValues, err := getBackyardsDemoObjects(options.namespace, options.values)
 	if err != nil {
 		return err
 	}
 	objects.Sort(helm.InstallObjectOrder())
 
+	labels := k8s.OwnershipLabels(demoappReleaseName, componentName, options.namespace)
+	k8s.LabelResources(objects, labels)
+
 	if !options.DumpResources {
 		client, err := cli.GetK8sClient()
 		if err != nil {
 			return err
 		}
 
+		if options.DryRun.Enabled() {
+			var pruned object.K8sObjects
+			if options.Prune {
+				pruned, err = k8s.PruneResources(client, objects, k8s.GroupVersionKinds(objects), labels, true)
+				if err != nil {
+					return err
+				}
+			}
+
+			return util.DryRun(cli, client, options.DryRun, objects, pruned)
+		}
+
 		err = k8s.ApplyResources(client, objects)
 		if err != nil {
 			return err
@@ -129,6 +155,18 @@ func (c *installCommand) run(cli cli.CLI, options *InstallOptions) error {
 		if err != nil {
 			return err
 		}
+
+		if options.Prune {
+			_, err = k8s.PruneResources(client, objects, k8s.GroupVersionKinds(objects), labels, false)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = util.RecordRelease(cli, client, demoappReleaseName, objects, rawValues, "")
+		if err != nil {
+			return err
+		}
 	} else {
 		yaml, err := objects.YAMLManifest()
 		if err != nil {
@@ -140,37 +178,39 @@ func (c *installCommand) run(cli cli.CLI, options *InstallOptions) error {
 	return nil
 }
 
-func getBackyardsDemoObjects(namespace string) (object.K8sObjects, error) {
+// getBackyardsDemoObjects renders the chart of the demo application, the values it was rendered with are returned in
+// YAML format as well
+func getBackyardsDemoObjects(namespace string, overrides helm.ValueOverrides) (object.K8sObjects, string, error) {
 	var values Values
 
 	valuesYAML, err := helm.GetDefaultValues(backyards_demo.Chart)
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not get helm default values")
+		return nil, "", errors.WrapIf(err, "could not get helm default values")
 	}
 
 	err = yaml.Unmarshal(valuesYAML, &values)
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not unmarshal yaml values")
+		return nil, "", errors.WrapIf(err, "could not unmarshal yaml values")
 	}
 
 	values.UseNamespaceResource = true
 
-	rawValues, err := yaml.Marshal(values)
+	rawValues, err := overrides.Merge(&values)
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not marshal yaml values")
+		return nil, "", err
 	}
 
-	objects, err := helm.Render(backyards_demo.Chart, string(rawValues), helm.ReleaseOptions{
+	objects, err := helm.Render(backyards_demo.Chart, rawValues, helm.ReleaseOptions{
 		Name:      "backyards-demo",
 		IsInstall: true,
 		IsUpgrade: false,
 		Namespace: namespace,
 	}, "backyards-demo")
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not render helm manifest objects")
+		return nil, "", errors.WrapIf(err, "could not render helm manifest objects")
 	}
 
-	return objects, nil
+	return objects, rawValues, nil
 }
 
 func (c *installCommand) validate(istioNamespace string) error {
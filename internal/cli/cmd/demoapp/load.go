@@ -78,14 +78,9 @@ func (c *loadCommand) run(cli cli.CLI, options *LoadOptions) error {
 	var err error
 	var response graphql.GenerateLoadResponse
 
-	pf, err := cli.GetPortforwardForIGW(0)
+	client, err := cli.GetGraphQLClient()
 	if err != nil {
-		return err
-	}
-
-	err = pf.Run()
-	if err != nil {
-		return err
+		return errors.WrapIf(err, "could not get initialized graphql client")
 	}
 
 	var wg sync.WaitGroup
@@ -95,8 +90,7 @@ func (c *loadCommand) run(cli cli.CLI, options *LoadOptions) error {
 		"duration": options.Duration,
 	}).Info("sending load to demo application")
 	go func() {
-		client := graphql.NewClient(pf.GetURL("/api/graphql"))
-		response, err = client.GenerateLoad(graphql.GenerateLoadRequest{
+		response, err = client.GenerateLoad(cli.Context(), graphql.GenerateLoadRequest{
 			Namespace: options.namespace,
 			Service:   "frontpage",
 			Port:      8080,
@@ -0,0 +1,91 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package demoapp
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli/clitest"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql/graphqltest"
+)
+
+func TestLoadCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		respond  func(s *graphqltest.Server)
+		wantErr  bool
+		wantVars map[string]interface{}
+	}{
+		{
+			name:    "sends load with the default settings",
+			args:    []string{},
+			respond: func(s *graphqltest.Server) { s.Respond("generateLoad", map[string]int{"200": 300}) },
+			wantVars: map[string]interface{}{
+				"namespace": backyardsDemoNamespace,
+				"service":   "frontpage",
+				"frequency": float64(10),
+				"duration":  float64(30),
+			},
+		},
+		{
+			name:    "sends load with the given frequency and duration",
+			args:    []string{"--rps=20", "--duration=5"},
+			respond: func(s *graphqltest.Server) { s.Respond("generateLoad", map[string]int{"200": 100}) },
+			wantVars: map[string]interface{}{
+				"frequency": float64(20),
+				"duration":  float64(5),
+			},
+		},
+		{
+			name: "returns backend errors",
+			args: []string{},
+			respond: func(s *graphqltest.Server) {
+				s.RespondError("generateLoad", "NOT_FOUND", "service frontpage not found")
+			},
+			wantErr:  true,
+			wantVars: map[string]interface{}{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			server := graphqltest.NewServer()
+			defer server.Close()
+			test.respond(server)
+
+			cmd := NewLoadCommand(clitest.NewFakeCLI(nil, server.Client()), NewLoadOptions())
+			cmd.SetArgs(test.args)
+			cmd.SetOutput(ioutil.Discard)
+
+			err := cmd.Execute()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			mutations := server.Mutations()
+			if len(mutations) != 1 || mutations[0].Field != "generateLoad" {
+				t.Fatalf("expected a single generateLoad mutation, got %+v", mutations)
+			}
+			for name, value := range test.wantVars {
+				if mutations[0].Variables[name] != value {
+					t.Errorf("unexpected value of %s: %v", name, mutations[0].Variables[name])
+				}
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"istio.io/operator/pkg/object"
 	"k8s.io/apimachinery/pkg/util/wait"
 
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/util"
 	"github.com/banzaicloud/backyards-cli/pkg/cli"
 	"github.com/banzaicloud/backyards-cli/pkg/helm"
 	"github.com/banzaicloud/backyards-cli/pkg/k8s"
@@ -36,6 +37,7 @@ type UninstallOptions struct {
 	namespace string
 
 	DumpResources bool
+	DryRun        k8s.DryRunStrategy
 }
 
 func NewUninstallOptions() *UninstallOptions {
@@ -73,22 +75,40 @@ It can only dump the removable resources with the '--dump-resources' option.`,
 	}
 
 	cmd.Flags().BoolVarP(&options.DumpResources, "dump-resources", "d", options.DumpResources, "Dump resources to stdout instead of applying them")
+	options.DryRun.AddFlag(cmd.Flags())
 
 	return cmd
 }
 
 func (c *uninstallCommand) run(cli cli.CLI, options *UninstallOptions) error {
-	objects, err := getBackyardsDemoObjects(options.namespace)
+	client, err := cli.GetK8sClient()
+	if err != nil {
+		return err
+	}
+
+	// releases installed before the CLI kept records are removed by rendering the chart with the default values
+	objects, err := util.GetInstalledObjects(client, demoappReleaseName, func() (object.K8sObjects, error) {
+		objects, _, err := getBackyardsDemoObjects(options.namespace, helm.ValueOverrides{})
+		return objects, err
+	})
 	if err != nil {
 		return err
 	}
 	objects.Sort(helm.UninstallObjectOrder())
 
 	if !options.DumpResources {
-		err := c.deleteResources(objects)
+		err := c.deleteResources(objects, options.DryRun)
 		if err != nil {
 			return errors.WrapIf(err, "could not delete k8s resources")
 		}
+
+		if !options.DryRun.Enabled() {
+			err = util.GetReleaseStore(client).Delete(demoappReleaseName)
+			if err != nil {
+				return errors.WrapIf(err, "could not delete release records")
+			}
+		}
+
 		return nil
 	}
 
@@ -101,12 +121,16 @@ func (c *uninstallCommand) run(cli cli.CLI, options *UninstallOptions) error {
 	return nil
 }
 
-func (c *uninstallCommand) deleteResources(objects object.K8sObjects) error {
+func (c *uninstallCommand) deleteResources(objects object.K8sObjects, dryRun k8s.DryRunStrategy) error {
 	client, err := c.cli.GetK8sClient()
 	if err != nil {
 		return err
 	}
 
+	if dryRun.Enabled() {
+		return util.DryRun(c.cli, client, dryRun, nil, objects)
+	}
+
 	err = k8s.DeleteResources(client, objects, k8s.WaitForResourceConditions(wait.Backoff{
 		Duration: time.Second * 5,
 		Factor:   1,
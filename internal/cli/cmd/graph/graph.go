@@ -15,9 +15,14 @@ package graph
 
 import (
 	"context"
+	"net/http"
+	"sort"
 	"strings"
 	"time"
 
+	"emperror.dev/errors"
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -28,6 +33,7 @@ import (
 	"github.com/waynz0r/grafterm/pkg/service/metric"
 	metricdatasource "github.com/waynz0r/grafterm/pkg/service/metric/datasource"
 	metricmiddleware "github.com/waynz0r/grafterm/pkg/service/metric/middleware"
+	metricprometheus "github.com/waynz0r/grafterm/pkg/service/metric/prometheus"
 	"github.com/waynz0r/grafterm/pkg/view"
 	"github.com/waynz0r/grafterm/pkg/view/page"
 	"github.com/waynz0r/grafterm/pkg/view/render"
@@ -38,25 +44,34 @@ import (
 	"github.com/banzaicloud/backyards-cli/pkg/cli"
 )
 
-var (
+const templateFileExtension = ".json"
+
+// circuit breaker trips are only reported by the proxy of the caller
+var sourceReportedTemplates = map[string]bool{
+	"cb": true,
+}
+
+type graphOptions struct {
+	serviceID       string
+	template        string
 	titleSuffix     string
 	outbound        bool
 	refreshInterval time.Duration
 	relativeDur     time.Duration
-)
-
-type graphOptions struct {
-	serviceID string
 
 	serviceName types.NamespacedName
 }
 
-func newGraphOptions() *graphOptions {
-	return &graphOptions{}
+func newGraphOptions(template string) *graphOptions {
+	return &graphOptions{
+		template:        template,
+		refreshInterval: 10 * time.Second,
+		relativeDur:     15 * time.Minute,
+	}
 }
 
 func NewGraphCmd(cli cli.CLI, fileName string) *cobra.Command {
-	options := newGraphOptions()
+	options := newGraphOptions(strings.TrimSuffix(fileName, templateFileExtension))
 
 	cmd := &cobra.Command{
 		Use:   "graph [[--service=]namespace/servicename]",
@@ -79,27 +94,27 @@ func NewGraphCmd(cli cli.CLI, fileName string) *cobra.Command {
 				}
 			}
 
-			f, err := graphtemplates.GraphTemplates.Open(fileName)
-			if err != nil {
-				return err
+			if !cmd.Flags().Changed("outbound") && sourceReportedTemplates[options.template] {
+				options.outbound = true
 			}
 
-			cfg, err := configuration.JSONLoader{}.Load(f)
+			f, err := openTemplate(options.template)
 			if err != nil {
 				return err
 			}
+			defer f.Close()
 
-			ddss, err := cfg.Datasources()
+			cfg, err := configuration.JSONLoader{}.Load(f)
 			if err != nil {
 				return err
 			}
 
-			pf, err := cli.GetPortforwardForIGW(0)
+			ddss, err := cfg.Datasources()
 			if err != nil {
 				return err
 			}
 
-			err = pf.Run()
+			endpoint, err := cli.GetEndpoint(0)
 			if err != nil {
 				return err
 			}
@@ -109,13 +124,13 @@ func NewGraphCmd(cli cli.CLI, fileName string) *cobra.Command {
 					ID: "ds",
 					DatasourceSource: model.DatasourceSource{
 						Prometheus: &model.PrometheusDatasource{
-							Address: pf.GetURL("/prometheus"),
+							Address: endpoint.URLForPath("/prometheus"),
 						},
 					},
 				},
 			}
 
-			gatherer, err := createGatherer(ddss, udss)
+			gatherer, err := createGatherer(ddss, udss, endpoint.HTTPClient().Transport)
 			if err != nil {
 				return err
 			}
@@ -133,8 +148,8 @@ func NewGraphCmd(cli cli.CLI, fileName string) *cobra.Command {
 			defer renderer.Close()
 
 			appcfg := view.AppConfig{
-				RefreshInterval:   refreshInterval,
-				RelativeTimeRange: relativeDur,
+				RefreshInterval:   options.refreshInterval,
+				RelativeTimeRange: options.relativeDur,
 			}
 
 			ds, err := cfg.Dashboard()
@@ -156,18 +171,55 @@ func NewGraphCmd(cli cli.CLI, fileName string) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&titleSuffix, "title-suffix", "", "Title suffix")
-	cmd.Flags().BoolVar(&outbound, "outbound", false, "Whether to show outbound or inbound metrics")
-	cmd.Flags().DurationVarP(&refreshInterval, "refresh-interval", "r", 10*time.Second, "the interval to refresh the dashboard")
-	cmd.Flags().DurationVarP(&relativeDur, "relative-duration", "d", 15*time.Minute, "the relative duration from now to load the graph")
+	cmd.Flags().StringVar(&options.template, "template", options.template, "Name of the dashboard template to show ("+strings.Join(listTemplates(), ", ")+")")
+	cmd.Flags().StringVar(&options.titleSuffix, "title-suffix", "", "Title suffix")
+	cmd.Flags().BoolVar(&options.outbound, "outbound", false, "Whether to show outbound or inbound metrics (outbound by default for the cb template)")
+	cmd.Flags().DurationVarP(&options.refreshInterval, "refresh-interval", "r", options.refreshInterval, "the interval to refresh the dashboard")
+	cmd.Flags().DurationVarP(&options.relativeDur, "relative-duration", "d", options.relativeDur, "the relative duration from now to load the graph")
 
 	return cmd
 }
 
+// openTemplate opens an embedded dashboard template by name
+func openTemplate(name string) (http.File, error) {
+	for _, template := range listTemplates() {
+		if template == name {
+			return graphtemplates.GraphTemplates.Open(name + templateFileExtension)
+		}
+	}
+
+	return nil, errors.Errorf("unknown graph template: '%s': must be one of %s", name, strings.Join(listTemplates(), ", "))
+}
+
+// listTemplates returns the names of the embedded dashboard templates
+func listTemplates() []string {
+	templates := make([]string, 0)
+
+	dir, err := graphtemplates.GraphTemplates.Open("/")
+	if err != nil {
+		return templates
+	}
+	defer dir.Close()
+
+	files, err := dir.Readdir(-1)
+	if err != nil {
+		return templates
+	}
+
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), templateFileExtension) {
+			templates = append(templates, strings.TrimSuffix(file.Name(), templateFileExtension))
+		}
+	}
+	sort.Strings(templates)
+
+	return templates
+}
+
 func getFilter(options *graphOptions) string {
 	filters := make([]string, 0)
 
-	if outbound {
+	if options.outbound {
 		filters = append(filters, "reporter=\"source\"")
 	} else {
 		filters = append(filters, "reporter=\"destination\"")
@@ -185,12 +237,12 @@ func getFilter(options *graphOptions) string {
 }
 
 func getTitleSuffix(options *graphOptions) string {
-	if titleSuffix != "" {
-		return titleSuffix
+	if options.titleSuffix != "" {
+		return options.titleSuffix
 	}
 
 	s := make([]string, 0)
-	if outbound {
+	if options.outbound {
 		s = append(s, "outbound")
 	} else {
 		s = append(s, "inbound")
@@ -207,10 +259,24 @@ func getTitleSuffix(options *graphOptions) string {
 	return strings.Join(s, " / ")
 }
 
-func createGatherer(dashboardDss, userDss []model.Datasource) (metric.Gatherer, error) {
+func createGatherer(dashboardDss, userDss []model.Datasource, roundTripper http.RoundTripper) (metric.Gatherer, error) {
 	gatherer, err := metricdatasource.NewGatherer(metricdatasource.ConfigGatherer{
 		DashboardDatasources: dashboardDss,
 		UserDatasources:      userDss,
+		// the Prometheus of Backyards may be reached through an ingress with custom TLS settings
+		CreatePrometheusFunc: func(ds model.PrometheusDatasource) (metric.Gatherer, error) {
+			client, err := promapi.NewClient(promapi.Config{
+				Address:      ds.Address,
+				RoundTripper: roundTripper,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return metricprometheus.NewGatherer(metricprometheus.ConfigGatherer{
+				Client: promv1.NewAPI(client),
+			}), nil
+		},
 	})
 	if err != nil {
 		return nil, err
@@ -223,10 +289,10 @@ func createGatherer(dashboardDss, userDss []model.Datasource) (metric.Gatherer,
 func createApp(ctx context.Context, appCfg view.AppConfig, dashboard model.Dashboard, ctrl controller.Controller, renderer render.Renderer, options *graphOptions) (*view.App, error) {
 
 	filter := getFilter(options)
-	titleSuffix = " " + getTitleSuffix(options)
+	titleSuffix := " " + getTitleSuffix(options)
 
 	dashCfg := page.DashboardCfg{
-		AppRelativeTimeRange: relativeDur,
+		AppRelativeTimeRange: options.relativeDur,
 		AppOverrideVariables: map[string]string{
 			"titleSuffix": titleSuffix,
 			"filter":      filter,
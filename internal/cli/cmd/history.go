@@ -0,0 +1,107 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"time"
+
+	"emperror.dev/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/util"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/output"
+)
+
+type historyCommand struct{}
+
+type historyOptions struct {
+	releaseName string
+}
+
+type revision struct {
+	Revision int    `json:"revision"`
+	Updated  string `json:"updated"`
+	Status   string `json:"status"`
+	Version  string `json:"version"`
+}
+
+func newHistoryOptions() *historyOptions {
+	return &historyOptions{
+		releaseName: defaultReleaseName,
+	}
+}
+
+func NewHistoryCommand(cli cli.CLI) *cobra.Command {
+	c := &historyCommand{}
+	options := newHistoryOptions()
+
+	cmd := &cobra.Command{
+		Use:           "history [flags]",
+		Args:          cobra.NoArgs,
+		Short:         "Show the revisions of the Backyards release",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.run(cli, options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.releaseName, "release-name", options.releaseName, "Name of the release")
+
+	return cmd
+}
+
+func (c *historyCommand) run(cli cli.CLI, options *historyOptions) error {
+	client, err := cli.GetK8sClient()
+	if err != nil {
+		return err
+	}
+
+	releases, err := util.GetReleaseStore(client).History(options.releaseName)
+	if err != nil {
+		return err
+	}
+
+	if len(releases) == 0 {
+		log.Infof("no revisions found for release %s", options.releaseName)
+		return nil
+	}
+
+	data := make([]revision, 0, len(releases))
+	for _, r := range releases {
+		data = append(data, revision{
+			Revision: r.Revision,
+			Updated:  r.Timestamp.Local().Format(time.RFC1123),
+			Status:   r.Status,
+			Version:  r.Version,
+		})
+	}
+
+	ctx := &output.Context{
+		Out:     cli.Out(),
+		Color:   cli.Color(),
+		Format:  cli.OutputFormat(),
+		Fields:  []string{"Revision", "Updated", "Status", "Version"},
+		Headers: []string{"Revision", "Updated", "Status", "CLI version"},
+	}
+
+	err = output.Output(ctx, data)
+	if err != nil {
+		return errors.WrapIf(err, "could not produce output")
+	}
+
+	return nil
+}
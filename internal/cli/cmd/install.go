@@ -23,6 +23,7 @@ import (
 
 	"emperror.dev/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"go.uber.org/multierr"
 	"istio.io/operator/pkg/object"
@@ -47,6 +48,9 @@ import (
 const (
 	requirementNotFoundErrorTemplate = "Unable to install Backyards: %s\n"
 	defaultReleaseName               = "backyards"
+	// helmReleaseName is the name of the release the chart is rendered with, which is independent of --release-name
+	helmReleaseName = "backyards"
+	componentName   = "backyards"
 )
 
 var (
@@ -63,6 +67,8 @@ type InstallOptions struct {
 	releaseName    string
 	istioNamespace string
 	dumpResources  bool
+	prune          bool
+	dryRun         k8s.DryRunStrategy
 
 	installCanary      bool
 	installDemoapp     bool
@@ -72,6 +78,10 @@ type InstallOptions struct {
 	disableAuditSink   bool
 	installEverything  bool
 	runDemo            bool
+
+	values helm.ValueOverrides
+	// flagValues are the values of the explicitly set flags in key=value format
+	flagValues []string
 }
 
 // patchStringValue specifies a patch operation for a string value
@@ -85,7 +95,9 @@ func NewInstallCommand(cli cli.CLI) *cobra.Command {
 	c := &installCommand{
 		cli: cli,
 	}
-	options := &InstallOptions{}
+	options := &InstallOptions{
+		prune: true,
+	}
 
 	cmd := &cobra.Command{
 		Use:   "install [flags]",
@@ -108,6 +120,8 @@ The command can install every component at once with the '--install-everything'
 			cmd.SilenceErrors = true
 			cmd.SilenceUsage = true
 
+			options.flagValues = getFlagValues(cmd.Flags(), options.disableCertManager, options.disableAuditSink)
+
 			err = c.runSubcommands(cli, options)
 			if err != nil {
 				return err
@@ -146,6 +160,10 @@ The command can install every component at once with the '--install-everything'
 	cmd.Flags().BoolVar(&options.disableAuditSink, "disable-auditsink", options.disableAuditSink, "Disable deploying the auditsink service and sending audit logs over http")
 
 	cmd.Flags().BoolVarP(&options.dumpResources, "dump-resources", "d", options.dumpResources, "Dump resources to stdout instead of applying them")
+	cmd.Flags().BoolVar(&options.prune, "prune", options.prune, "Delete the previously applied resources of the release which are not rendered any more")
+	options.dryRun.AddFlag(cmd.Flags())
+
+	options.values.AddFlags(cmd.Flags())
 
 	return cmd
 }
@@ -162,7 +180,12 @@ func (c *installCommand) run(cli cli.CLI, options *InstallOptions) error {
 		return nil
 	}
 
-	values, err := getValues(options.releaseName, options.istioNamespace, func(values *Values) {
+	userValues, err := getUserValues("", options.flagValues, options.values)
+	if err != nil {
+		return err
+	}
+
+	values, rawValues, err := getValues(options.releaseName, options.istioNamespace, userValues, helm.ValueOverrides{}, func(values *Values) {
 		values.CertManager.Enabled = !options.disableCertManager
 		values.AuditSink.Enabled = !options.disableAuditSink
 	})
@@ -170,24 +193,41 @@ func (c *installCommand) run(cli cli.CLI, options *InstallOptions) error {
 		return err
 	}
 
-	err = c.setTracingAddress(values)
-	if err != nil {
-		return err
+	if !options.dryRun.Enabled() {
+		err = setTracingAddress(cli, values)
+		if err != nil {
+			return err
+		}
 	}
 
-	objects, err := getBackyardsObjects(values)
+	objects, err := getBackyardsObjects(rawValues, false)
 	if err != nil {
 		return err
 	}
 
 	objects.Sort(helm.InstallObjectOrder())
 
+	labels := k8s.OwnershipLabels(options.releaseName, componentName, viper.GetString("backyards.namespace"))
+	k8s.LabelResources(objects, labels)
+
 	if !options.dumpResources {
 		client, err := cli.GetK8sClient()
 		if err != nil {
 			return err
 		}
 
+		if options.dryRun.Enabled() {
+			var pruned object.K8sObjects
+			if options.prune {
+				pruned, err = pruneResources(client, options.releaseName, objects, labels, true)
+				if err != nil {
+					return err
+				}
+			}
+
+			return util.DryRun(cli, client, options.dryRun, objects, pruned)
+		}
+
 		err = k8s.ApplyResources(client, objects)
 		if err != nil {
 			return err
@@ -202,6 +242,18 @@ func (c *installCommand) run(cli cli.CLI, options *InstallOptions) error {
 		if err != nil {
 			return err
 		}
+
+		if options.prune {
+			_, err = pruneResources(client, options.releaseName, objects, labels, false)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = util.RecordRelease(cli, client, options.releaseName, objects, rawValues, userValues)
+		if err != nil {
+			return err
+		}
 	} else {
 		yaml, err := objects.YAMLManifest()
 		if err != nil {
@@ -213,17 +265,45 @@ func (c *installCommand) run(cli cli.CLI, options *InstallOptions) error {
 	return nil
 }
 
-func getValues(releaseName, istioNamespace string, valueOverrideFunc func(values *Values)) (Values, error) {
+// getFlagValues returns the values of the explicitly set flags in --set format, so they are recorded as values given by the user
+func getFlagValues(flags *pflag.FlagSet, disableCertManager, disableAuditSink bool) []string {
+	values := make([]string, 0)
+	if flags.Changed("disable-cert-manager") {
+		values = append(values, fmt.Sprintf("certmanager.enabled=%t", !disableCertManager))
+	}
+	if flags.Changed("disable-auditsink") {
+		values = append(values, fmt.Sprintf("auditsink.enabled=%t", !disableAuditSink))
+	}
+
+	return values
+}
+
+// getUserValues returns the values given by the user in YAML format without the chart defaults: the base values, e.g. the
+// user values of the last release, the values of the explicitly set flags, then the values files and --set values on top
+func getUserValues(base string, flagValues []string, overrides helm.ValueOverrides) (string, error) {
+	overrides = helm.ValueOverrides{
+		ValueFiles: overrides.ValueFiles,
+		Values:     append(append([]string{}, flagValues...), overrides.Values...),
+	}
+
+	values := make(map[string]interface{})
+	return overrides.MergeWithBase(&values, base)
+}
+
+// getValues returns the values of the Backyards chart, the defaults of the CLI can be changed with valueOverrideFunc,
+// the base values in YAML format, e.g. the values of the last release, take precedence over both, and the user given
+// overrides take precedence over all of them. The values are returned in YAML format as well for rendering.
+func getValues(releaseName, istioNamespace string, base string, overrides helm.ValueOverrides, valueOverrideFunc func(values *Values)) (Values, string, error) {
 	var values Values
 
 	valuesYAML, err := helm.GetDefaultValues(backyards.Chart)
 	if err != nil {
-		return Values{}, errors.WrapIf(err, "could not get helm default values")
+		return Values{}, "", errors.WrapIf(err, "could not get helm default values")
 	}
 
 	err = yaml.Unmarshal(valuesYAML, &values)
 	if err != nil {
-		return Values{}, errors.WrapIf(err, "could not unmarshal yaml values")
+		return Values{}, "", errors.WrapIf(err, "could not unmarshal yaml values")
 	}
 
 	values.SetDefaults(releaseName, istioNamespace)
@@ -232,19 +312,19 @@ func getValues(releaseName, istioNamespace string, valueOverrideFunc func(values
 		valueOverrideFunc(&values)
 	}
 
-	return values, nil
-}
-
-func getBackyardsObjects(values Values) (object.K8sObjects, error) {
-	rawValues, err := yaml.Marshal(values)
+	rawValues, err := overrides.MergeWithBase(&values, base)
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not marshal yaml values")
+		return Values{}, "", err
 	}
 
-	objects, err := helm.Render(backyards.Chart, string(rawValues), helm.ReleaseOptions{
-		Name:      "backyards",
-		IsInstall: true,
-		IsUpgrade: false,
+	return values, rawValues, nil
+}
+
+func getBackyardsObjects(rawValues string, upgrade bool) (object.K8sObjects, error) {
+	objects, err := helm.Render(backyards.Chart, rawValues, helm.ReleaseOptions{
+		Name:      helmReleaseName,
+		IsInstall: !upgrade,
+		IsUpgrade: upgrade,
 		Namespace: viper.GetString("backyards.namespace"),
 	}, "backyards")
 	if err != nil {
@@ -254,8 +334,9 @@ func getBackyardsObjects(values Values) (object.K8sObjects, error) {
 	return objects, nil
 }
 
-func (c *installCommand) setTracingAddress(values Values) error {
-	cl, err := c.cli.GetK8sClient()
+// setTracingAddress points the tracing of the Istio mesh to the zipkin service of Backyards
+func setTracingAddress(cli cli.CLI, values Values) error {
+	cl, err := cli.GetK8sClient()
 	if err != nil {
 		err = errors.WrapIf(err, "could not get k8s client")
 		return err
@@ -375,7 +456,7 @@ func (c *installCommand) certManagerRunning() (exists bool, healthy bool, err er
 func (c *installCommand) runDemo(cli cli.CLI, options *InstallOptions) error {
 	var err error
 
-	if !options.runDemo || (!options.installEverything && !options.installDemoapp) {
+	if !options.runDemo || options.dryRun.Enabled() || (!options.installEverything && !options.installDemoapp) {
 		return nil
 	}
 
@@ -408,6 +489,8 @@ func (c *installCommand) runSubcommands(cli cli.CLI, options *InstallOptions) er
 		if options.dumpResources {
 			scmdOptions.DumpResources = true
 		}
+		scmdOptions.Prune = options.prune
+		scmdOptions.DryRun = options.dryRun
 		scmd = istio.NewInstallCommand(cli, scmdOptions)
 		err = scmd.RunE(scmd, nil)
 		if err != nil {
@@ -420,6 +503,8 @@ func (c *installCommand) runSubcommands(cli cli.CLI, options *InstallOptions) er
 		if options.dumpResources {
 			scmdOptions.DumpResources = true
 		}
+		scmdOptions.Prune = options.prune
+		scmdOptions.DryRun = options.dryRun
 		scmd = certmanager.NewInstallCommand(cli, scmdOptions)
 		err = scmd.RunE(scmd, nil)
 		if err != nil {
@@ -432,6 +517,8 @@ func (c *installCommand) runSubcommands(cli cli.CLI, options *InstallOptions) er
 		if options.dumpResources {
 			scmdOptions.DumpResources = true
 		}
+		scmdOptions.Prune = options.prune
+		scmdOptions.DryRun = options.dryRun
 		scmd = canary.NewInstallCommand(cli, scmdOptions)
 		err = scmd.RunE(scmd, nil)
 		if err != nil {
@@ -451,6 +538,8 @@ func (c *installCommand) runDemoInstall(cli cli.CLI, options *InstallOptions) er
 		if options.dumpResources {
 			scmdOptions.DumpResources = true
 		}
+		scmdOptions.Prune = options.prune
+		scmdOptions.DryRun = options.dryRun
 		scmd = demoapp.NewInstallCommand(cli, scmdOptions)
 		err = scmd.RunE(scmd, nil)
 		if err != nil {
@@ -0,0 +1,62 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/backyards-cli/pkg/helm"
+)
+
+func TestGetUserValues(t *testing.T) {
+	tests := map[string]struct {
+		base       string
+		flagValues []string
+		overrides  helm.ValueOverrides
+		expected   string
+	}{
+		"no values": {
+			expected: "{}\n",
+		},
+		"keeps the base values without the chart defaults": {
+			base:     "prometheus:\n  replicas: 2\n",
+			expected: "prometheus:\n  replicas: 2\n",
+		},
+		"flags take precedence over the base values": {
+			base:       "certmanager:\n  enabled: true\nprometheus:\n  replicas: 2\n",
+			flagValues: []string{"certmanager.enabled=false"},
+			expected:   "certmanager:\n  enabled: false\nprometheus:\n  replicas: 2\n",
+		},
+		"set values take precedence over the flags": {
+			flagValues: []string{"auditsink.enabled=false"},
+			overrides:  helm.ValueOverrides{Values: []string{"auditsink.enabled=true"}},
+			expected:   "auditsink:\n  enabled: true\n",
+		},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+
+		t.Run(name, func(t *testing.T) {
+			values, err := getUserValues(test.base, test.flagValues, test.overrides)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if values != test.expected {
+				t.Errorf("unexpected values:\n%s\nexpected:\n%s", values, test.expected)
+			}
+		})
+	}
+}
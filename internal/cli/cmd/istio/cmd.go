@@ -21,6 +21,8 @@ import (
 
 const (
 	DefaultNamespace = "istio-system"
+
+	componentName = "istio"
 )
 
 var (
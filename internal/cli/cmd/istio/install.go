@@ -34,15 +34,18 @@ import (
 
 	"github.com/banzaicloud/backyards-cli/cmd/backyards/static/istio_assets"
 	"github.com/banzaicloud/backyards-cli/cmd/backyards/static/istio_operator"
+	cmdutil "github.com/banzaicloud/backyards-cli/internal/cli/cmd/util"
 	"github.com/banzaicloud/backyards-cli/pkg/cli"
 	"github.com/banzaicloud/backyards-cli/pkg/helm"
 	"github.com/banzaicloud/backyards-cli/pkg/k8s"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
 	"github.com/banzaicloud/backyards-cli/pkg/util"
 	"github.com/banzaicloud/istio-operator/pkg/apis/istio/v1beta1"
 )
 
 const (
 	IstioCRName         = "mesh"
+	istioCRKind         = "Istio"
 	istioCRYamlFilename = "istio.yaml"
 )
 
@@ -59,13 +62,18 @@ type installCommand struct {
 
 type InstallOptions struct {
 	DumpResources bool
+	Prune         bool
+	DryRun        k8s.DryRunStrategy
 
 	istioCRFilename string
 	releaseName     string
+	values          helm.ValueOverrides
 }
 
 func NewInstallOptions() *InstallOptions {
-	return &InstallOptions{}
+	return &InstallOptions{
+		Prune: true,
+	}
 }
 
 func NewInstallCommand(cli cli.CLI, options *InstallOptions) *cobra.Command {
@@ -101,12 +109,16 @@ The installer automatically detects whether the CRDs are installed or not, and b
 	cmd.Flags().StringVarP(&options.istioCRFilename, "istio-cr-file", "f", "", "Filename of a custom Istio CR yaml")
 
 	cmd.Flags().BoolVarP(&options.DumpResources, "dump-resources", "d", options.DumpResources, "Dump resources to stdout instead of applying them")
+	cmd.Flags().BoolVar(&options.Prune, "prune", options.Prune, "Delete the previously applied resources of the release which are not rendered any more")
+	options.DryRun.AddFlag(cmd.Flags())
+
+	options.values.AddFlagsWithoutShorthand(cmd.Flags())
 
 	return cmd
 }
 
 func (c *installCommand) run(cli cli.CLI, options *InstallOptions) error {
-	objects, err := getIstioOperatorObjects(options.releaseName)
+	objects, rawValues, err := getIstioOperatorObjects(options.releaseName, options.values)
 	if err != nil {
 		return err
 	}
@@ -128,11 +140,27 @@ func (c *installCommand) run(cli cli.CLI, options *InstallOptions) error {
 	}
 	objs = append(objs, istioCRObj)
 
+	labels := k8s.OwnershipLabels(options.releaseName, componentName, IstioNamespace)
+	k8s.LabelResources(crds, labels)
+	k8s.LabelResources(objs, labels)
+
 	if !options.DumpResources {
-		err := c.applyResources(crds, objs)
+		err := c.applyResources(crds, objs, labels, options)
 		if err != nil {
 			return errors.WrapIf(err, "could not apply resources")
 		}
+
+		if !options.DryRun.Enabled() {
+			client, err := cli.GetK8sClient()
+			if err != nil {
+				return err
+			}
+
+			_, err = cmdutil.RecordRelease(cli, client, options.releaseName, append(crds, objs...), rawValues, "")
+			if err != nil {
+				return err
+			}
+		}
 	} else {
 		crdsExists, err := c.isCRDsExists(istioCRDs)
 		if err != nil {
@@ -158,12 +186,24 @@ func (c *installCommand) run(cli cli.CLI, options *InstallOptions) error {
 	return nil
 }
 
-func (c *installCommand) applyResources(crds, objects object.K8sObjects) error {
+func (c *installCommand) applyResources(crds, objects object.K8sObjects, labels map[string]string, options *InstallOptions) error {
 	client, err := c.cli.GetK8sClient()
 	if err != nil {
 		return err
 	}
 
+	if options.DryRun.Enabled() {
+		var pruned object.K8sObjects
+		if options.Prune {
+			pruned, err = k8s.PruneResources(client, objects, k8s.GroupVersionKinds(objects), labels, true)
+			if err != nil {
+				return err
+			}
+		}
+
+		return cmdutil.DryRun(c.cli, client, options.DryRun, append(crds, objects...), pruned)
+	}
+
 	// apply CRDs first
 	err = k8s.ApplyResources(client, crds)
 	if err != nil {
@@ -203,7 +243,19 @@ func (c *installCommand) applyResources(crds, objects object.K8sObjects) error {
 		return err
 	}
 
-	return nil
+	return c.pruneResources(client, objects, labels, options)
+}
+
+// pruneResources deletes the resources of the release which are not part of the objects any more,
+// the CRDs are left out since they are never pruned
+func (c *installCommand) pruneResources(client k8sclient.Client, objects object.K8sObjects, labels map[string]string, options *InstallOptions) error {
+	if !options.Prune {
+		return nil
+	}
+
+	_, err := k8s.PruneResources(client, objects, k8s.GroupVersionKinds(objects), labels, false)
+
+	return err
 }
 
 func (c *installCommand) getIstioDeploymentsToWaitFor() []k8s.NamespacedNameWithGVK {
@@ -259,37 +311,39 @@ func (c *installCommand) getIstioDeploymentsToWaitFor() []k8s.NamespacedNameWith
 	return deployments
 }
 
-func getIstioOperatorObjects(releaseName string) (object.K8sObjects, error) {
+// getIstioOperatorObjects renders the chart of the Istio operator, the values it was rendered with are returned in
+// YAML format as well
+func getIstioOperatorObjects(releaseName string, overrides helm.ValueOverrides) (object.K8sObjects, string, error) {
 	var values Values
 
 	valuesYAML, err := helm.GetDefaultValues(istio_operator.Chart)
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not get helm default values")
+		return nil, "", errors.WrapIf(err, "could not get helm default values")
 	}
 
 	err = yaml.Unmarshal(valuesYAML, &values)
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not unmarshal yaml values")
+		return nil, "", errors.WrapIf(err, "could not unmarshal yaml values")
 	}
 
 	values.SetDefaults(releaseName)
 
-	rawValues, err := yaml.Marshal(values)
+	rawValues, err := overrides.Merge(&values)
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not marshal yaml values")
+		return nil, "", err
 	}
 
-	objects, err := helm.Render(istio_operator.Chart, string(rawValues), helm.ReleaseOptions{
+	objects, err := helm.Render(istio_operator.Chart, rawValues, helm.ReleaseOptions{
 		Name:      "istio-operator",
 		IsInstall: true,
 		IsUpgrade: false,
 		Namespace: IstioNamespace,
 	}, "istio-operator")
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not render helm manifest objects")
+		return nil, "", errors.WrapIf(err, "could not render helm manifest objects")
 	}
 
-	return objects, nil
+	return objects, rawValues, nil
 }
 
 func getIstioCR(filename string) (*object.K8sObject, error) {
@@ -23,6 +23,7 @@ import (
 	"istio.io/operator/pkg/object"
 	"k8s.io/apimachinery/pkg/util/wait"
 
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/util"
 	"github.com/banzaicloud/backyards-cli/pkg/cli"
 	"github.com/banzaicloud/backyards-cli/pkg/helm"
 	"github.com/banzaicloud/backyards-cli/pkg/k8s"
@@ -36,6 +37,7 @@ type UninstallOptions struct {
 	releaseName string
 
 	DumpResources bool
+	DryRun        k8s.DryRunStrategy
 }
 
 func NewUninstallOptions() *UninstallOptions {
@@ -71,29 +73,38 @@ It can only dump the removable resources with the '--dump-resources' option.`,
 	cmd.Flags().StringVar(&options.releaseName, "release-name", "istio-operator", "Name of the release")
 
 	cmd.Flags().BoolVarP(&options.DumpResources, "dump-resources", "d", options.DumpResources, "Dump resources to stdout instead of applying them")
+	options.DryRun.AddFlag(cmd.Flags())
 
 	return cmd
 }
 
 func (c *uninstallCommand) run(cli cli.CLI, options *UninstallOptions) error {
-	objects, err := getIstioOperatorObjects(options.releaseName)
+	client, err := cli.GetK8sClient()
 	if err != nil {
 		return err
 	}
-	objects.Sort(helm.UninstallObjectOrder())
 
-	istioCRObj, err := getIstioCR("")
+	objects, err := util.GetInstalledObjects(client, options.releaseName, func() (object.K8sObjects, error) {
+		return getDefaultObjects(options.releaseName)
+	})
 	if err != nil {
 		return err
 	}
-
-	objects = append([]*object.K8sObject{istioCRObj}, objects...)
+	objects = uninstallOrder(objects)
 
 	if !options.DumpResources {
-		err := c.deleteResources(objects)
+		err := c.deleteResources(objects, options.DryRun)
 		if err != nil {
 			return errors.WrapIf(err, "could not delete k8s resources")
 		}
+
+		if !options.DryRun.Enabled() {
+			err = util.GetReleaseStore(client).Delete(options.releaseName)
+			if err != nil {
+				return errors.WrapIf(err, "could not delete release records")
+			}
+		}
+
 		return nil
 	}
 
@@ -106,12 +117,50 @@ func (c *uninstallCommand) run(cli cli.CLI, options *UninstallOptions) error {
 	return nil
 }
 
-func (c *uninstallCommand) deleteResources(objects object.K8sObjects) error {
+// getDefaultObjects returns the objects rendered with the default values and the default Istio CR, which are removed
+// for releases installed before the CLI kept records
+func getDefaultObjects(releaseName string) (object.K8sObjects, error) {
+	objects, _, err := getIstioOperatorObjects(releaseName, helm.ValueOverrides{})
+	if err != nil {
+		return nil, err
+	}
+
+	istioCRObj, err := getIstioCR("")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(objects, istioCRObj), nil
+}
+
+// uninstallOrder sorts the objects in uninstall order with the Istio CR in front, so that the operator
+// is still running to remove the resources of the mesh
+func uninstallOrder(objects object.K8sObjects) object.K8sObjects {
+	objects.Sort(helm.UninstallObjectOrder())
+
+	ordered := make(object.K8sObjects, 0, len(objects))
+	rest := make(object.K8sObjects, 0, len(objects))
+	for _, obj := range objects {
+		if obj.Kind == istioCRKind {
+			ordered = append(ordered, obj)
+		} else {
+			rest = append(rest, obj)
+		}
+	}
+
+	return append(ordered, rest...)
+}
+
+func (c *uninstallCommand) deleteResources(objects object.K8sObjects, dryRun k8s.DryRunStrategy) error {
 	client, err := c.cli.GetK8sClient()
 	if err != nil {
 		return err
 	}
 
+	if dryRun.Enabled() {
+		return util.DryRun(c.cli, client, dryRun, nil, objects)
+	}
+
 	err = k8s.DeleteResources(client, objects, k8s.WaitForResourceConditions(wait.Backoff{
 		Duration: time.Second * 5,
 		Factor:   1,
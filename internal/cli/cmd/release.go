@@ -0,0 +1,59 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"emperror.dev/errors"
+	"istio.io/operator/pkg/object"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/util"
+	"github.com/banzaicloud/backyards-cli/pkg/k8s"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+	"github.com/banzaicloud/backyards-cli/pkg/release"
+)
+
+// getPreviousObjects returns the objects of the last revision of the release. Releases installed before the CLI
+// kept records have no revisions, for those the objects applied by the CLI with the label of the release are returned.
+func getPreviousObjects(client k8sclient.Client, releaseName string, objects object.K8sObjects) (object.K8sObjects, error) {
+	last, err := util.GetReleaseStore(client).Last(releaseName)
+	if err == nil {
+		return util.GetReleaseObjects(last)
+	}
+	if !release.IsNotFound(err) {
+		return nil, err
+	}
+
+	previous, err := k8s.ListAppliedResources(client, k8s.GroupVersionKinds(objects), map[string]string{
+		"app.kubernetes.io/instance": helmReleaseName,
+	})
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list resources of the previous release")
+	}
+
+	return previous, nil
+}
+
+// pruneResources deletes the objects with the ownership labels which are not part of the objects any more. Besides the
+// kinds of the objects, the kinds of the last revision are looked at as well, to prune kinds which are not rendered at all.
+func pruneResources(client k8sclient.Client, releaseName string, objects object.K8sObjects, labels map[string]string, dryRun bool) (object.K8sObjects, error) {
+	previous, err := getPreviousObjects(client, releaseName, objects)
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := k8s.GroupVersionKinds(append(append(object.K8sObjects{}, objects...), previous...))
+
+	return k8s.PruneResources(client, objects, kinds, labels, dryRun)
+}
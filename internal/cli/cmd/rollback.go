@@ -0,0 +1,161 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"emperror.dev/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/util"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/k8s"
+	"github.com/banzaicloud/backyards-cli/pkg/release"
+)
+
+type rollbackCommand struct{}
+
+type rollbackOptions struct {
+	releaseName string
+	revision    int
+	yes         bool
+	prune       bool
+	dryRun      k8s.DryRunStrategy
+}
+
+func newRollbackOptions() *rollbackOptions {
+	return &rollbackOptions{
+		releaseName: defaultReleaseName,
+		prune:       true,
+	}
+}
+
+func NewRollbackCommand(cli cli.CLI) *cobra.Command {
+	c := &rollbackCommand{}
+	options := newRollbackOptions()
+
+	cmd := &cobra.Command{
+		Use:   "rollback [flags]",
+		Args:  cobra.NoArgs,
+		Short: "Roll Backyards back to a previous revision",
+		Long: `Rolls Backyards back to a previous revision of the release.
+
+The resources of the revision are compared with the ones in the cluster and the differences
+are shown before applying them, just like on upgrade. The rollback is recorded as a new revision.`,
+		Example: `  # Roll back to the previous revision.
+  backyards rollback
+
+  # Roll back to the first revision without confirmation.
+  backyards rollback --revision 1 --yes`,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.revision < 0 {
+				return errors.New("revision must be positive")
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.releaseName, "release-name", options.releaseName, "Name of the release")
+	flags.IntVar(&options.revision, "revision", options.revision, "Revision to roll back to, the one before the current revision if not set")
+	flags.BoolVarP(&options.yes, "yes", "y", options.yes, "Roll back without asking for confirmation")
+	flags.BoolVar(&options.prune, "prune", options.prune, "Delete the previously applied resources of the release which are not part of the revision")
+	options.dryRun.AddFlag(flags)
+
+	return cmd
+}
+
+func (c *rollbackCommand) run(cli cli.CLI, options *rollbackOptions) error {
+	client, err := cli.GetK8sClient()
+	if err != nil {
+		return err
+	}
+
+	store := util.GetReleaseStore(client)
+
+	current, err := store.Last(options.releaseName)
+	if err != nil {
+		return err
+	}
+
+	revision := options.revision
+	if revision == 0 {
+		revision = current.Revision - 1
+		if revision == 0 {
+			return errors.NewWithDetails("there is no previous revision to roll back to", "release", options.releaseName)
+		}
+	}
+	if revision == current.Revision {
+		return errors.NewWithDetails("revision is the current revision", "release", options.releaseName, "revision", revision)
+	}
+
+	target, err := store.Get(options.releaseName, revision)
+	if err != nil {
+		if release.IsNotFound(err) {
+			return err
+		}
+		return errors.WrapIf(err, "could not get revision")
+	}
+
+	objects, err := util.GetReleaseObjects(target)
+	if err != nil {
+		return err
+	}
+
+	// revisions recorded before the objects had ownership labels are labeled as well
+	labels := k8s.OwnershipLabels(options.releaseName, componentName, viper.GetString("backyards.namespace"))
+	k8s.LabelResources(objects, labels)
+
+	previous, err := util.GetReleaseObjects(current)
+	if err != nil {
+		return err
+	}
+
+	applied, err := applyChanges(cli, client, objects, previous, applyOptions{
+		yes:    options.yes,
+		prune:  options.prune,
+		dryRun: options.dryRun,
+		labels: labels,
+	})
+	if err != nil || options.dryRun.Enabled() {
+		return err
+	}
+
+	r, err := util.RecordRelease(cli, client, options.releaseName, objects, target.Values, target.UserValues)
+	if err != nil {
+		return err
+	}
+
+	if applied {
+		var values Values
+		err = yaml.Unmarshal([]byte(target.Values), &values)
+		if err != nil {
+			return errors.WrapIf(err, "could not unmarshal values of revision")
+		}
+
+		err = setTracingAddress(cli, values)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Infof("rolled back to revision %d as revision %d", target.Revision, r.Revision)
+
+	return nil
+}
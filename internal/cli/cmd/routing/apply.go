@@ -0,0 +1,343 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+
+	"emperror.dev/errors"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/cb"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/ts"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+	"github.com/banzaicloud/backyards-cli/pkg/output"
+)
+
+const (
+	applyStatusConfigured = "configured"
+	applyStatusUnchanged  = "unchanged"
+	applyStatusPruned     = "pruned"
+	applyStatusFailed     = "failed"
+)
+
+type applyCommand struct{}
+
+type applyOptions struct {
+	filename string
+	prune    bool
+}
+
+type ApplyResult struct {
+	Service string `json:"service"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+func newApplyOptions() *applyOptions {
+	return &applyOptions{}
+}
+
+func newApplyCommand(cli cli.CLI) *cobra.Command {
+	c := &applyCommand{}
+	options := newApplyOptions()
+
+	cmd := &cobra.Command{
+		Use:   "apply -f filename",
+		Short: "Apply traffic shifting and circuit breaker rules from a routing config file",
+		Long: `Apply traffic shifting and circuit breaker rules from a routing config file.
+
+The rules of every service in the file are reconciled to match the file: traffic shifting
+rules and circuit breaker settings missing from the file are removed from the service.
+With --prune the rules of services in the same namespaces which are not in the file are removed as well.`,
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.filename == "" {
+				return errors.New("filename must be specified")
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&options.filename, "filename", "f", "", "Routing config file to apply, - reads from the standard input")
+	flags.BoolVar(&options.prune, "prune", options.prune, "Remove the routing rules of services in the same namespaces which are not in the file")
+
+	return cmd
+}
+
+func (c *applyCommand) run(cli cli.CLI, options *applyOptions) error {
+	var err error
+
+	config, err := readRoutingConfig(options.filename)
+	if err != nil {
+		return err
+	}
+
+	client, err := cli.GetGraphQLClient()
+	if err != nil {
+		return errors.WrapIf(err, "could not get initialized graphql client")
+	}
+
+	results := make([]ApplyResult, 0)
+	services := make(map[string]bool)
+	namespaces := make(map[string]bool)
+	for _, desired := range config.Services {
+		// the service IDs are already validated
+		serviceName, _ := common.ParseServiceID(desired.Service)
+		services[serviceName.String()] = true
+		namespaces[serviceName.Namespace] = true
+
+		result := ApplyResult{
+			Service: serviceName.String(),
+		}
+
+		result.Status, err = c.applyServiceRoutingConfig(cli, client, serviceName, desired)
+		if err != nil {
+			result.Status = applyStatusFailed
+			result.Error = err.Error()
+		}
+
+		results = append(results, result)
+	}
+
+	if options.prune {
+		for namespace := range namespaces {
+			current, err := getServiceRoutingConfigs(cli, namespace)
+			if err != nil {
+				return err
+			}
+
+			for _, s := range current {
+				if services[s.Service] {
+					continue
+				}
+
+				result := ApplyResult{
+					Service: s.Service,
+					Status:  applyStatusPruned,
+				}
+
+				err = c.deleteServiceRoutingConfig(cli.Context(), client, s)
+				if err != nil {
+					result.Status = applyStatusFailed
+					result.Error = err.Error()
+				}
+
+				results = append(results, result)
+			}
+		}
+	}
+
+	ctx := &output.Context{
+		Out:     cli.Out(),
+		Color:   cli.Color(),
+		Format:  cli.OutputFormat(),
+		Fields:  []string{"Service", "Status", "Error"},
+		Headers: []string{"Service", "Status", "Error"},
+	}
+
+	err = output.Output(ctx, results)
+	if err != nil {
+		return errors.WrapIf(err, "could not produce output")
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Status == applyStatusFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("could not apply routing config to %d service(s)", failed)
+	}
+
+	return nil
+}
+
+func (c *applyCommand) applyServiceRoutingConfig(cli cli.CLI, client graphql.Client, serviceName types.NamespacedName, desired ServiceRoutingConfig) (string, error) {
+	_, err := common.GetServiceByName(cli, serviceName)
+	if err != nil {
+		if k8serrors.IsNotFound(errors.Cause(err)) {
+			return "", errors.Errorf("service '%s' not found", serviceName)
+		}
+		return "", errors.WrapIf(err, "could not get service")
+	}
+
+	current, err := getServiceRoutingConfig(cli, serviceName)
+	if err != nil {
+		return "", err
+	}
+
+	status := applyStatusUnchanged
+
+	currentRules := make(map[string]ts.TrafficShiftingRule)
+	for _, rule := range current.TrafficShifting {
+		currentRules[rule.Matches.String()] = rule
+	}
+
+	desiredRules := make(map[string]bool)
+	for _, rule := range desired.TrafficShifting {
+		desiredRules[rule.Matches.String()] = true
+
+		if r, ok := currentRules[rule.Matches.String()]; ok && reflect.DeepEqual(r.Weights, rule.Weights) {
+			continue
+		}
+
+		match, err := rule.Matches.Parse()
+		if err != nil {
+			return "", err
+		}
+
+		r, err := client.ApplyHTTPRoute(cli.Context(), ts.NewApplyHTTPRouteRequest(serviceName, match, rule.Weights))
+		if err != nil {
+			return "", err
+		}
+		if !r {
+			return "", errors.Errorf("unknown error: cannot set traffic shifting matching %s", rule.Matches)
+		}
+		status = applyStatusConfigured
+	}
+
+	for _, rule := range current.TrafficShifting {
+		if desiredRules[rule.Matches.String()] {
+			continue
+		}
+
+		err = deleteTrafficShiftingRule(cli.Context(), client, serviceName, rule)
+		if err != nil {
+			return "", err
+		}
+		status = applyStatusConfigured
+	}
+
+	switch {
+	case desired.CircuitBreaker != nil && !reflect.DeepEqual(desired.CircuitBreaker, current.CircuitBreaker):
+		r, err := client.ApplyGlobalTrafficPolicy(cli.Context(), cb.NewApplyGlobalTrafficPolicyRequest(serviceName, *desired.CircuitBreaker))
+		if err != nil {
+			return "", err
+		}
+		if !r {
+			return "", errors.New("unknown error: cannot apply circuit breaker settings")
+		}
+		status = applyStatusConfigured
+	case desired.CircuitBreaker == nil && current.CircuitBreaker != nil:
+		err = deleteCircuitBreakerSettings(cli.Context(), client, serviceName)
+		if err != nil {
+			return "", err
+		}
+		status = applyStatusConfigured
+	}
+
+	return status, nil
+}
+
+func (c *applyCommand) deleteServiceRoutingConfig(ctx context.Context, client graphql.Client, config ServiceRoutingConfig) error {
+	serviceName, err := common.ParseServiceID(config.Service)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range config.TrafficShifting {
+		err = deleteTrafficShiftingRule(ctx, client, serviceName, rule)
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.CircuitBreaker != nil {
+		err = deleteCircuitBreakerSettings(ctx, client, serviceName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deleteTrafficShiftingRule(ctx context.Context, client graphql.Client, serviceName types.NamespacedName, rule ts.TrafficShiftingRule) error {
+	match, err := rule.Matches.Parse()
+	if err != nil {
+		return err
+	}
+
+	r, err := client.DisableHTTPRoute(ctx, graphql.DisableHTTPRouteRequest{
+		Name:      serviceName.Name,
+		Namespace: serviceName.Namespace,
+		Match:     match,
+		Rules:     []string{"Route"},
+	})
+	if err != nil {
+		return err
+	}
+	if !r {
+		return errors.Errorf("unknown error: cannot delete traffic shifting matching %s", rule.Matches)
+	}
+
+	return nil
+}
+
+func deleteCircuitBreakerSettings(ctx context.Context, client graphql.Client, serviceName types.NamespacedName) error {
+	r, err := client.DisableGlobalTrafficPolicy(ctx, graphql.DisableGlobalTrafficPolicyRequest{
+		Name:      serviceName.Name,
+		Namespace: serviceName.Namespace,
+		Rules:     []string{"ConnectionPool", "OutlierDetection"},
+	})
+	if err != nil {
+		return err
+	}
+	if !r {
+		return errors.New("unknown error: cannot delete circuit breaker settings")
+	}
+
+	return nil
+}
+
+func readRoutingConfig(filename string) (*RoutingConfig, error) {
+	var err error
+	var data []byte
+
+	if filename == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not read routing config")
+	}
+
+	var config RoutingConfig
+	err = yaml.UnmarshalStrict(data, &config)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not parse routing config")
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,125 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli/clitest"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql/graphqltest"
+)
+
+func TestApplyCommandPrune(t *testing.T) {
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "backyards-demo"}
+	}
+	weighted := []v1alpha3.HTTPRoute{{
+		Route: []v1alpha3.HTTPRouteDestination{
+			{Destination: v1alpha3.Destination{Host: "movies", Subset: "v1"}, Weight: 50},
+			{Destination: v1alpha3.Destination{Host: "movies", Subset: "v2"}, Weight: 50},
+		},
+	}}
+	circuitBreaker := &v1alpha3.TrafficPolicy{
+		ConnectionPool: &v1alpha3.ConnectionPoolSettings{TCP: &v1alpha3.TCPSettings{MaxConnections: 10}},
+	}
+
+	objects := []runtime.Object{
+		&corev1.Service{ObjectMeta: meta("movies")},
+		&corev1.Service{ObjectMeta: meta("bookings")},
+		// managed by the routing commands, pruned
+		&v1alpha3.VirtualService{ObjectMeta: meta("movies"), Spec: v1alpha3.VirtualServiceSpec{
+			Hosts: []string{"movies"},
+			HTTP:  weighted,
+		}},
+		// bound to a gateway
+		&v1alpha3.VirtualService{ObjectMeta: meta("ingress"), Spec: v1alpha3.VirtualServiceSpec{
+			Hosts:    []string{"*"},
+			Gateways: []string{"backyards-demo-gateway"},
+			HTTP:     weighted,
+		}},
+		// not named after its host
+		&v1alpha3.VirtualService{ObjectMeta: meta("bookings"), Spec: v1alpha3.VirtualServiceSpec{
+			Hosts: []string{"movies.backyards-demo.svc.cluster.local"},
+			HTTP:  weighted,
+		}},
+		// no such service
+		&v1alpha3.VirtualService{ObjectMeta: meta("legacy"), Spec: v1alpha3.VirtualServiceSpec{
+			Hosts: []string{"legacy"},
+			HTTP:  weighted,
+		}},
+		&v1alpha3.DestinationRule{ObjectMeta: meta("external"), Spec: v1alpha3.DestinationRuleSpec{
+			Host:          "api.example.com",
+			TrafficPolicy: circuitBreaker,
+		}},
+	}
+
+	f, err := ioutil.TempFile("", "routing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(`version: v1alpha1
+services:
+- service: backyards-demo/bookings
+  trafficShifting:
+  - weights:
+      v1: 100
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	server := graphqltest.NewServer()
+	defer server.Close()
+	server.Respond("applyHTTPRoute", true)
+	server.Respond("disableHTTPRoute", true)
+	server.Respond("disableGlobalTrafficPolicy", true)
+
+	cmd := newApplyCommand(clitest.NewFakeCLI(clitest.NewK8sClient(objects...), server.Client()))
+	cmd.SetArgs([]string{"-f", f.Name(), "--prune"})
+	cmd.SetOutput(ioutil.Discard)
+
+	err = cmd.Execute()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mutations := server.Mutations()
+	if len(mutations) != 2 {
+		t.Fatalf("expected 2 mutations, got %+v", mutations)
+	}
+	if mutations[0].Field != "applyHTTPRoute" {
+		t.Errorf("expected the rules of bookings to be applied, got %s", mutations[0].Field)
+	}
+	if mutations[1].Field != "disableHTTPRoute" {
+		t.Fatalf("expected only the traffic shifting rules of movies to be pruned, got %s", mutations[1].Field)
+	}
+	var req graphql.DisableHTTPRouteRequest
+	if err := mutations[1].DecodeVariable("input", &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Name != "movies" || req.Namespace != "backyards-demo" {
+		t.Errorf("expected the rules of backyards-demo/movies to be pruned, got %s/%s", req.Namespace, req.Name)
+	}
+}
@@ -32,6 +32,7 @@ func NewRootCmd(cli cli.CLI) *cobra.Command {
 		newGetCommand(cli),
 		newSetCommand(cli),
 		newDeleteCommand(cli),
+		newStatusCommand(cli),
 		graph.NewGraphCmd(cli, "cb.json"),
 	)
 
@@ -31,9 +31,12 @@ import (
 type deleteCommand struct{}
 
 type deleteOptions struct {
-	serviceID string
+	serviceID        string
+	connectionPool   bool
+	outlierDetection bool
 
 	serviceName types.NamespacedName
+	rules       []string
 }
 
 func newDeleteOptions() *deleteOptions {
@@ -47,6 +50,7 @@ func newDeleteCommand(cli cli.CLI) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:           "delete [[--service=]namespace/servicename]",
 		Short:         "Delete circuit breaker rules of a service",
+		Long:          "Delete circuit breaker rules of a service. Both the connection pool and the outlier detection settings are deleted, unless one of them is selected.",
 		Args:          cobra.MaximumNArgs(1),
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -65,12 +69,24 @@ func newDeleteCommand(cli cli.CLI) *cobra.Command {
 				return err
 			}
 
+			if options.connectionPool {
+				options.rules = append(options.rules, "ConnectionPool")
+			}
+			if options.outlierDetection {
+				options.rules = append(options.rules, "OutlierDetection")
+			}
+			if len(options.rules) == 0 {
+				options.rules = []string{"ConnectionPool", "OutlierDetection"}
+			}
+
 			return c.run(cli, options)
 		},
 	}
 
 	flags := cmd.Flags()
 	flags.StringVar(&options.serviceID, "service", "", "Service name")
+	flags.BoolVar(&options.connectionPool, "connection-pool", options.connectionPool, "Delete the connection pool settings")
+	flags.BoolVar(&options.outlierDetection, "outlier-detection", options.outlierDetection, "Delete the outlier detection settings")
 
 	return cmd
 }
@@ -113,7 +129,7 @@ func (c *deleteCommand) run(cli cli.CLI, options *deleteOptions) error {
 		}
 	}
 
-	client, err := common.GetGraphQLClient(cli)
+	client, err := cli.GetGraphQLClient()
 	if err != nil {
 		return errors.WrapIf(err, "could not get initialized graphql client")
 	}
@@ -121,9 +137,9 @@ func (c *deleteCommand) run(cli cli.CLI, options *deleteOptions) error {
 	req := graphql.DisableGlobalTrafficPolicyRequest{
 		Name:      service.Name,
 		Namespace: service.Namespace,
-		Rules:     []string{"ConnectionPool", "OutlierDetection"},
+		Rules:     options.rules,
 	}
-	r, err := client.DisableGlobalTrafficPolicy(req)
+	r, err := client.DisableGlobalTrafficPolicy(cli.Context(), req)
 	if err != nil {
 		return err
 	}
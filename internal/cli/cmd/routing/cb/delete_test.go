@@ -0,0 +1,64 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cb
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli/clitest"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+)
+
+func TestDeleteCommand(t *testing.T) {
+	clitest.MutationTests{
+		Field:      "disableGlobalTrafficPolicy",
+		NewCommand: newDeleteCommand,
+		Objects:    testObjects(nil),
+		Cases: []clitest.MutationTestCase{
+			{
+				Name: "deletes every circuit breaker rule by default",
+				Args: []string{"backyards-demo/movies"},
+				WantInput: &graphql.DisableGlobalTrafficPolicyRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Rules:     []string{"ConnectionPool", "OutlierDetection"},
+				},
+			},
+			{
+				Name: "deletes the connection pool settings only",
+				Args: []string{"backyards-demo/movies", "--connection-pool"},
+				WantInput: &graphql.DisableGlobalTrafficPolicyRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Rules:     []string{"ConnectionPool"},
+				},
+			},
+			{
+				Name: "deletes the outlier detection settings only",
+				Args: []string{"backyards-demo/movies", "--outlier-detection"},
+				WantInput: &graphql.DisableGlobalTrafficPolicyRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Rules:     []string{"OutlierDetection"},
+				},
+			},
+			{
+				Name:    "fails for unknown services",
+				Args:    []string{"backyards-demo/books"},
+				WantErr: true,
+			},
+		},
+	}.Run(t)
+}
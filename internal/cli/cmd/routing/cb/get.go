@@ -20,6 +20,7 @@ import (
 	"github.com/spf13/cobra"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
 
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
 	clierrors "github.com/banzaicloud/backyards-cli/internal/errors"
@@ -85,37 +86,50 @@ func getCircuitBreakerRulesByServiceName(cli cli.CLI, serviceName types.Namespac
 	}
 
 	drule, err := common.GetDestinationRuleByName(cli, serviceName)
-	notfound := false
 	if err != nil {
 		if k8serrors.IsNotFound(errors.Cause(err)) {
-			notfound = true
-		} else {
-			return nil, errors.WrapIf(err, "could not get service")
+			return nil, clierrors.NotFoundError{}
 		}
-	} else if drule.Spec.TrafficPolicy == nil || drule.Spec.TrafficPolicy.ConnectionPool == nil {
-		notfound = true
+		return nil, errors.WrapIf(err, "could not get service")
 	}
 
-	if notfound {
+	settings := GetCircuitBreakerSettings(drule.Spec.TrafficPolicy)
+	if settings == nil {
 		return nil, clierrors.NotFoundError{}
 	}
 
-	tp := drule.Spec.TrafficPolicy
+	return settings, nil
+}
 
-	return &CircuitBreakerSettings{
-		MaxConnections: tp.ConnectionPool.TCP.MaxConnections,
-		ConnectTimeout: tp.ConnectionPool.TCP.ConnectTimeout,
+// GetCircuitBreakerSettings returns the circuit breaker settings of a traffic policy,
+// or nil if neither connection pool settings nor outlier detection is set
+func GetCircuitBreakerSettings(tp *v1alpha3.TrafficPolicy) *CircuitBreakerSettings {
+	if tp == nil || (tp.ConnectionPool == nil && tp.OutlierDetection == nil) {
+		return nil
+	}
 
-		HTTP1MaxPendingRequests:  tp.ConnectionPool.HTTP.HTTP1MaxPendingRequests,
-		HTTP2MaxRequests:         tp.ConnectionPool.HTTP.HTTP2MaxRequests,
-		MaxRequestsPerConnection: tp.ConnectionPool.HTTP.MaxRequestsPerConnection,
-		MaxRetries:               tp.ConnectionPool.HTTP.MaxRetries,
+	settings := &CircuitBreakerSettings{}
+
+	if tp.ConnectionPool != nil && tp.ConnectionPool.TCP != nil {
+		settings.MaxConnections = tp.ConnectionPool.TCP.MaxConnections
+		settings.ConnectTimeout = tp.ConnectionPool.TCP.ConnectTimeout
+	}
+
+	if tp.ConnectionPool != nil && tp.ConnectionPool.HTTP != nil {
+		settings.HTTP1MaxPendingRequests = tp.ConnectionPool.HTTP.HTTP1MaxPendingRequests
+		settings.HTTP2MaxRequests = tp.ConnectionPool.HTTP.HTTP2MaxRequests
+		settings.MaxRequestsPerConnection = tp.ConnectionPool.HTTP.MaxRequestsPerConnection
+		settings.MaxRetries = tp.ConnectionPool.HTTP.MaxRetries
+	}
+
+	if tp.OutlierDetection != nil {
+		settings.ConsecutiveErrors = tp.OutlierDetection.ConsecutiveErrors
+		settings.Interval = tp.OutlierDetection.Interval
+		settings.BaseEjectionTime = tp.OutlierDetection.BaseEjectionTime
+		settings.MaxEjectionPercent = tp.OutlierDetection.MaxEjectionPercent
+	}
 
-		ConsecutiveErrors:  tp.OutlierDetection.ConsecutiveErrors,
-		Interval:           tp.OutlierDetection.Interval,
-		BaseEjectionTime:   tp.OutlierDetection.BaseEjectionTime,
-		MaxEjectionPercent: tp.OutlierDetection.MaxEjectionPercent,
-	}, nil
+	return settings
 }
 
 func (c *getCommand) run(cli cli.CLI, options *getOptions) error {
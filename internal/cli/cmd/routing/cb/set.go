@@ -52,6 +52,11 @@ type CircuitBreakerSettings struct {
 	MaxEjectionPercent int32  `json:"maxEjectionPercent,omitempty" yaml:"maxEjectionPercent,omitempty" survey.question:"Maximum ejection percentage"`
 }
 
+var (
+	connectionPoolFlags   = []string{"max-connections", "connect-timeout", "max-pending-requests", "max-requests", "max-requests-per-connection", "max-retries"}
+	outlierDetectionFlags = []string{"consecutiveErrors", "interval", "baseEjectionTime", "maxEjectionPercent"}
+)
+
 type setOptions struct {
 	serviceID string
 
@@ -79,7 +84,7 @@ func newSetOptions() *setOptions {
 		},
 		connectTimeout:   3 * time.Second,
 		interval:         10 * time.Second,
-		baseEjectionTime: 30 * time.Second,
+		baseEjectionTime: 10 * time.Second,
 	}
 }
 
@@ -88,9 +93,10 @@ func newSetCommand(cli cli.CLI) *cobra.Command {
 	options := newSetOptions()
 
 	cmd := &cobra.Command{
-		Use:           "set [[--service=]namespace/servicename] [[--version=]subset=weight] ...",
-		Short:         "Set traffic shifting rules for a service",
-		Args:          cobra.ArbitraryArgs,
+		Use:           "set [[--service=]namespace/servicename]",
+		Short:         "Set circuit breaker rules for a service",
+		Long:          "Set circuit breaker rules for a service. Only the explicitly specified settings are changed, the others keep their current values.",
+		Args:          cobra.MaximumNArgs(1),
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var err error
@@ -103,15 +109,17 @@ func newSetCommand(cli cli.CLI) *cobra.Command {
 				return errors.New("service must be specified")
 			}
 
-			options.ConnectTimeout = options.connectTimeout.String()
-			options.BaseEjectionTime = options.baseEjectionTime.String()
-			options.Interval = options.interval.String()
-
 			options.serviceName, err = common.ParseServiceID(options.serviceID)
 			if err != nil {
 				return err
 			}
 
+			current, err := getCircuitBreakerRulesByServiceName(cli, options.serviceName)
+			if err != nil && !clierrors.IsNotFound(err) {
+				return err
+			}
+			options.CircuitBreakerSettings = options.merge(cmd.Flags().Changed, withDefaults(cmd.Flags().Changed, current))
+
 			err = c.askQuestions(cli, options)
 			if err != nil {
 				return err
@@ -143,6 +151,86 @@ func newSetCommand(cli cli.CLI) *cobra.Command {
 	return cmd
 }
 
+// HasConnectionPool returns whether any of the connection pool settings is set
+func (s CircuitBreakerSettings) HasConnectionPool() bool {
+	return s.MaxConnections != 0 || s.ConnectTimeout != "" || s.HTTP1MaxPendingRequests != 0 ||
+		s.HTTP2MaxRequests != 0 || s.MaxRequestsPerConnection != 0 || s.MaxRetries != 0
+}
+
+// HasOutlierDetection returns whether any of the outlier detection settings is set
+func (s CircuitBreakerSettings) HasOutlierDetection() bool {
+	return s.ConsecutiveErrors != 0 || s.Interval != "" || s.BaseEjectionTime != "" || s.MaxEjectionPercent != 0
+}
+
+// withDefaults returns the current settings, the sections which are not set but have explicitly set flags are filled with
+// the defaults, the others are left empty. If neither the settings nor the flags are set, every section gets the defaults.
+func withDefaults(changed func(name string) bool, current *CircuitBreakerSettings) CircuitBreakerSettings {
+	defaults := newSetOptions().CircuitBreakerSettings
+
+	var settings CircuitBreakerSettings
+	if current != nil {
+		settings = *current
+	}
+
+	connectionPool := anyChanged(changed, connectionPoolFlags)
+	outlierDetection := anyChanged(changed, outlierDetectionFlags)
+	if !settings.HasConnectionPool() && !settings.HasOutlierDetection() && !connectionPool && !outlierDetection {
+		return defaults
+	}
+
+	if connectionPool && !settings.HasConnectionPool() {
+		settings.MaxConnections = defaults.MaxConnections
+		settings.ConnectTimeout = defaults.ConnectTimeout
+		settings.HTTP1MaxPendingRequests = defaults.HTTP1MaxPendingRequests
+		settings.HTTP2MaxRequests = defaults.HTTP2MaxRequests
+		settings.MaxRequestsPerConnection = defaults.MaxRequestsPerConnection
+		settings.MaxRetries = defaults.MaxRetries
+	}
+
+	if outlierDetection && !settings.HasOutlierDetection() {
+		settings.ConsecutiveErrors = defaults.ConsecutiveErrors
+		settings.Interval = defaults.Interval
+		settings.BaseEjectionTime = defaults.BaseEjectionTime
+		settings.MaxEjectionPercent = defaults.MaxEjectionPercent
+	}
+
+	return settings
+}
+
+func anyChanged(changed func(name string) bool, names []string) bool {
+	for _, name := range names {
+		if changed(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// merge returns the given settings overridden by the values of the explicitly set flags
+func (o *setOptions) merge(changed func(name string) bool, settings CircuitBreakerSettings) CircuitBreakerSettings {
+	setters := map[string]func(){
+		"max-connections":             func() { settings.MaxConnections = o.MaxConnections },
+		"connect-timeout":             func() { settings.ConnectTimeout = o.connectTimeout.String() },
+		"max-pending-requests":        func() { settings.HTTP1MaxPendingRequests = o.HTTP1MaxPendingRequests },
+		"max-requests":                func() { settings.HTTP2MaxRequests = o.HTTP2MaxRequests },
+		"max-requests-per-connection": func() { settings.MaxRequestsPerConnection = o.MaxRequestsPerConnection },
+		"max-retries":                 func() { settings.MaxRetries = o.MaxRetries },
+		"consecutiveErrors":           func() { settings.ConsecutiveErrors = o.ConsecutiveErrors },
+		"interval":                    func() { settings.Interval = o.interval.String() },
+		"baseEjectionTime":            func() { settings.BaseEjectionTime = o.baseEjectionTime.String() },
+		"maxEjectionPercent":          func() { settings.MaxEjectionPercent = o.MaxEjectionPercent },
+	}
+
+	for name, set := range setters {
+		if changed(name) {
+			set()
+		}
+	}
+
+	return settings
+}
+
 func (c *setCommand) askQuestions(cli cli.CLI, options *setOptions) error {
 	var err error
 
@@ -174,35 +262,17 @@ func (c *setCommand) run(cli cli.CLI, options *setOptions) error {
 		return errors.WrapIf(err, "could not get service")
 	}
 
-	client, err := common.GetGraphQLClient(cli)
+	client, err := cli.GetGraphQLClient()
 	if err != nil {
 		return errors.WrapIf(err, "could not get initialized graphql client")
 	}
 
-	req := graphql.ApplyGlobalTrafficPolicyRequest{
+	req := NewApplyGlobalTrafficPolicyRequest(types.NamespacedName{
 		Name:      service.Name,
 		Namespace: service.Namespace,
-		ConnectionPool: &v1alpha3.ConnectionPoolSettings{
-			TCP: &v1alpha3.TCPSettings{
-				MaxConnections: options.MaxConnections,
-				ConnectTimeout: options.ConnectTimeout,
-			},
-			HTTP: &v1alpha3.HTTPSettings{
-				HTTP1MaxPendingRequests:  options.HTTP1MaxPendingRequests,
-				HTTP2MaxRequests:         options.HTTP2MaxRequests,
-				MaxRequestsPerConnection: options.MaxRequestsPerConnection,
-				MaxRetries:               options.MaxRetries,
-			},
-		},
-		OutlierDetection: &v1alpha3.OutlierDetection{
-			ConsecutiveErrors:  options.ConsecutiveErrors,
-			Interval:           options.Interval,
-			BaseEjectionTime:   options.BaseEjectionTime,
-			MaxEjectionPercent: options.MaxEjectionPercent,
-		},
-	}
+	}, options.CircuitBreakerSettings)
 
-	r, err := client.ApplyGlobalTrafficPolicy(req)
+	r, err := client.ApplyGlobalTrafficPolicy(cli.Context(), req)
 	if err != nil {
 		return err
 	}
@@ -240,3 +310,38 @@ func (c *setCommand) output(cli cli.CLI, options *setOptions) error {
 
 	return nil
 }
+
+// NewApplyGlobalTrafficPolicyRequest returns the request which sets the circuit breaker settings of a service,
+// only the sections which have any settings are sent
+func NewApplyGlobalTrafficPolicyRequest(serviceName types.NamespacedName, settings CircuitBreakerSettings) graphql.ApplyGlobalTrafficPolicyRequest {
+	req := graphql.ApplyGlobalTrafficPolicyRequest{
+		Name:      serviceName.Name,
+		Namespace: serviceName.Namespace,
+	}
+
+	if settings.HasConnectionPool() {
+		req.ConnectionPool = &v1alpha3.ConnectionPoolSettings{
+			TCP: &v1alpha3.TCPSettings{
+				MaxConnections: settings.MaxConnections,
+				ConnectTimeout: settings.ConnectTimeout,
+			},
+			HTTP: &v1alpha3.HTTPSettings{
+				HTTP1MaxPendingRequests:  settings.HTTP1MaxPendingRequests,
+				HTTP2MaxRequests:         settings.HTTP2MaxRequests,
+				MaxRequestsPerConnection: settings.MaxRequestsPerConnection,
+				MaxRetries:               settings.MaxRetries,
+			},
+		}
+	}
+
+	if settings.HasOutlierDetection() {
+		req.OutlierDetection = &v1alpha3.OutlierDetection{
+			ConsecutiveErrors:  settings.ConsecutiveErrors,
+			Interval:           settings.Interval,
+			BaseEjectionTime:   settings.BaseEjectionTime,
+			MaxEjectionPercent: settings.MaxEjectionPercent,
+		}
+	}
+
+	return req
+}
@@ -0,0 +1,189 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cb
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli/clitest"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql/graphqltest"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+)
+
+func TestSetOptionsMerge(t *testing.T) {
+	current := &CircuitBreakerSettings{
+		MaxConnections: 10,
+		ConnectTimeout: "1s",
+	}
+
+	options := newSetOptions()
+	options.MaxConnections = 20
+	options.MaxRetries = 5
+	options.interval = time.Minute
+
+	changed := func(name string) bool {
+		return name == "max-connections" || name == "interval"
+	}
+
+	settings := options.merge(changed, withDefaults(changed, current))
+
+	expected := newSetOptions().CircuitBreakerSettings
+	expected.MaxConnections = 20
+	expected.ConnectTimeout = "1s"
+	expected.HTTP1MaxPendingRequests = 0
+	expected.HTTP2MaxRequests = 0
+	expected.MaxRequestsPerConnection = 0
+	expected.MaxRetries = 0
+	expected.Interval = "1m0s"
+
+	if settings != expected {
+		t.Errorf("unexpected settings\ngot : %+v\nwant: %+v", settings, expected)
+	}
+}
+
+func TestWithDefaults(t *testing.T) {
+	defaults := newSetOptions().CircuitBreakerSettings
+	connectionPool := CircuitBreakerSettings{MaxConnections: 10, ConnectTimeout: "1s"}
+	unchanged := func(name string) bool { return false }
+
+	tests := map[string]struct {
+		current  *CircuitBreakerSettings
+		changed  func(name string) bool
+		expected CircuitBreakerSettings
+	}{
+		"no settings and no flags": {
+			changed:  unchanged,
+			expected: defaults,
+		},
+		"keeps the existing sections only": {
+			current:  &connectionPool,
+			changed:  unchanged,
+			expected: connectionPool,
+		},
+		"adds the sections with explicitly set flags": {
+			changed: func(name string) bool { return name == "consecutiveErrors" },
+			expected: CircuitBreakerSettings{
+				ConsecutiveErrors:  defaults.ConsecutiveErrors,
+				Interval:           defaults.Interval,
+				BaseEjectionTime:   defaults.BaseEjectionTime,
+				MaxEjectionPercent: defaults.MaxEjectionPercent,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+
+		t.Run(name, func(t *testing.T) {
+			settings := withDefaults(test.changed, test.current)
+			if settings != test.expected {
+				t.Errorf("unexpected settings\ngot : %+v\nwant: %+v", settings, test.expected)
+			}
+		})
+	}
+}
+
+func testObjects(trafficPolicy *v1alpha3.TrafficPolicy) []runtime.Object {
+	objects := []runtime.Object{clitest.NewDemoService()}
+	if trafficPolicy != nil {
+		drule := clitest.NewDemoDestinationRule()
+		drule.Spec.TrafficPolicy = trafficPolicy
+		objects = append(objects, drule)
+	}
+
+	return objects
+}
+
+// checkRequest decodes the request of the mutation and checks that it is sent for the movies service
+func checkRequest(check func(t *testing.T, req graphql.ApplyGlobalTrafficPolicyRequest)) func(t *testing.T, mutation graphqltest.Request, k8sClient k8sclient.Client) {
+	return func(t *testing.T, mutation graphqltest.Request, k8sClient k8sclient.Client) {
+		var req graphql.ApplyGlobalTrafficPolicyRequest
+		if err := mutation.DecodeVariable("input", &req); err != nil {
+			t.Fatal(err)
+		}
+		if req.Name != "movies" || req.Namespace != "backyards-demo" {
+			t.Errorf("unexpected service: %s/%s", req.Namespace, req.Name)
+		}
+		check(t, req)
+	}
+}
+
+func TestSetCommand(t *testing.T) {
+	clitest.MutationTests{
+		Field:      "applyGlobalTrafficPolicy",
+		NewCommand: newSetCommand,
+		Objects:    testObjects(nil),
+		Cases: []clitest.MutationTestCase{
+			{
+				Name: "keeps the current settings which are not specified",
+				Args: []string{"backyards-demo/movies", "--max-connections=20"},
+				Objects: testObjects(&v1alpha3.TrafficPolicy{
+					ConnectionPool: &v1alpha3.ConnectionPoolSettings{
+						TCP: &v1alpha3.TCPSettings{MaxConnections: 10, ConnectTimeout: "1s"},
+					},
+				}),
+				Check: checkRequest(func(t *testing.T, req graphql.ApplyGlobalTrafficPolicyRequest) {
+					tcp := req.ConnectionPool.TCP
+					if tcp.MaxConnections != 20 || tcp.ConnectTimeout != "1s" {
+						t.Errorf("unexpected tcp settings: %+v", tcp)
+					}
+					if req.OutlierDetection != nil {
+						t.Errorf("unexpected outlier detection settings: %+v", req.OutlierDetection)
+					}
+				}),
+			},
+			{
+				Name: "uses the defaults for the sections of the specified settings",
+				Args: []string{"backyards-demo/movies", "--consecutiveErrors=3"},
+				Check: checkRequest(func(t *testing.T, req graphql.ApplyGlobalTrafficPolicyRequest) {
+					if req.ConnectionPool != nil {
+						t.Errorf("unexpected connection pool settings: %+v", req.ConnectionPool)
+					}
+					if od := req.OutlierDetection; od.ConsecutiveErrors != 3 || od.BaseEjectionTime != "10s" {
+						t.Errorf("unexpected outlier detection settings: %+v", od)
+					}
+				}),
+			},
+			{
+				Name: "uses the defaults if there are no settings",
+				Args: []string{"backyards-demo/movies"},
+				Check: checkRequest(func(t *testing.T, req graphql.ApplyGlobalTrafficPolicyRequest) {
+					if req.ConnectionPool.TCP.MaxConnections != 1024 || req.OutlierDetection.ConsecutiveErrors != 5 {
+						t.Errorf("unexpected settings: %+v %+v", req.ConnectionPool.TCP, req.OutlierDetection)
+					}
+				}),
+			},
+			{
+				Name:    "fails for unknown services",
+				Args:    []string{"backyards-demo/books"},
+				WantErr: true,
+			},
+			{
+				Name: "returns backend errors",
+				Args: []string{"backyards-demo/movies"},
+				Respond: func(s *graphqltest.Server) {
+					s.RespondError("applyGlobalTrafficPolicy", "BAD_USER_INPUT", "invalid interval")
+				},
+				WantErr: true,
+				Check:   checkRequest(func(t *testing.T, req graphql.ApplyGlobalTrafficPolicyRequest) {}),
+			},
+		},
+	}.Run(t)
+}
@@ -0,0 +1,239 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"emperror.dev/errors"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/output"
+)
+
+const (
+	// upstream overflow, set by the proxy of the caller when the circuit breaker trips
+	responseFlagUpstreamOverflow = "UO"
+	// no healthy upstream, set by the proxy of the caller when every host is ejected
+	responseFlagNoHealthyUpstream = "UH"
+)
+
+type statusCommand struct{}
+
+type statusOptions struct {
+	serviceID string
+	interval  time.Duration
+
+	serviceName types.NamespacedName
+}
+
+type CircuitBreakerStatus struct {
+	Source                string  `json:"source"`
+	RequestRate           float64 `json:"requestRate"`
+	TripRate              float64 `json:"tripRate"`
+	TripPercentage        float64 `json:"tripPercentage"`
+	PendingOverflowRate   float64 `json:"pendingOverflowRate"`
+	NoHealthyUpstreamRate float64 `json:"noHealthyUpstreamRate"`
+	EjectedHosts          float64 `json:"ejectedHosts"`
+}
+
+func newStatusOptions() *statusOptions {
+	return &statusOptions{
+		interval: time.Minute,
+	}
+}
+
+func newStatusCommand(cli cli.CLI) *cobra.Command {
+	c := &statusCommand{}
+	options := newStatusOptions()
+
+	cmd := &cobra.Command{
+		Use:   "status [[--service=]namespace/servicename]",
+		Short: "Show circuit breaker trips of a service by source app",
+		Long: `Show circuit breaker trips of a service by source app.
+
+Trips are requests rejected by the proxy of the caller because the connection pool or
+the pending request queue of the service overflowed, the latter are also shown separately
+as pending overflows. The hosts of the service currently ejected by outlier detection in the
+proxies of the caller are shown as ejected hosts, and the requests rejected because every
+host is ejected are shown as no healthy upstream.`,
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			if len(args) > 0 {
+				options.serviceID = args[0]
+			}
+
+			if options.serviceID == "" {
+				return errors.New("service must be specified")
+			}
+
+			options.serviceName, err = common.ParseServiceID(options.serviceID)
+			if err != nil {
+				return err
+			}
+
+			if options.interval < time.Second {
+				return errors.New("interval must be at least 1s")
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.serviceID, "service", "", "Service name")
+	flags.DurationVar(&options.interval, "interval", options.interval, "Time range to calculate the rates over")
+
+	return cmd
+}
+
+func (c *statusCommand) run(cli cli.CLI, options *statusOptions) error {
+	var err error
+
+	_, err = common.GetServiceByName(cli, options.serviceName)
+	if err != nil {
+		if k8serrors.IsNotFound(errors.Cause(err)) {
+			return err
+		}
+		return errors.WrapIf(err, "could not get service")
+	}
+
+	api, err := common.GetPrometheusAPI(cli)
+	if err != nil {
+		return errors.WrapIf(err, "could not get initialized prometheus client")
+	}
+
+	data, err := getCircuitBreakerStatuses(cli.Context(), api, options.serviceName, options.interval)
+	if err != nil {
+		return err
+	}
+
+	if len(data) == 0 {
+		log.Infof("no requests to %s in the last %s", options.serviceName, model.Duration(options.interval))
+		return nil
+	}
+
+	ctx := &output.Context{
+		Out:     cli.Out(),
+		Color:   cli.Color(),
+		Format:  cli.OutputFormat(),
+		Fields:  []string{"Source", "RequestRate", "TripRate", "TripPercentage", "PendingOverflowRate", "NoHealthyUpstreamRate", "EjectedHosts"},
+		Headers: []string{"Source", "Requests/s", "Trips/s", "Trips %", "Pending overflows/s", "No healthy upstream/s", "Ejected hosts"},
+	}
+
+	err = output.Output(ctx, data)
+	if err != nil {
+		return errors.WrapIf(err, "could not produce output")
+	}
+
+	return nil
+}
+
+// getCircuitBreakerStatuses returns the circuit breaker metrics of the callers of a service, calculated over the given interval
+func getCircuitBreakerStatuses(ctx context.Context, api promv1.API, serviceName types.NamespacedName, rateInterval time.Duration) ([]CircuitBreakerStatus, error) {
+	// requests rejected by the circuit breaker never reach the service, so they are only reported by the source
+	filter := fmt.Sprintf(`reporter="source",destination_service_namespace=%q,destination_service_name=%q`,
+		serviceName.Namespace, serviceName.Name)
+	interval := model.Duration(rateInterval).String()
+	query := func(extraFilter string) string {
+		return fmt.Sprintf("sum(rate(istio_requests_total{%s%s}[%s])) by (source_workload_namespace, source_app)",
+			filter, extraFilter, interval)
+	}
+
+	// the proxy metrics of the callers are labeled with the outbound cluster of every port and subset of the service
+	clusterFilter := fmt.Sprintf("upstream_cluster=~%q", fmt.Sprintf(`outbound\|[0-9]+\|[^|]*\|%s`,
+		regexp.QuoteMeta(common.GetFQDN(serviceName.Name, serviceName.Namespace))))
+
+	statuses := make(map[string]*CircuitBreakerStatus)
+	for _, q := range []struct {
+		query          string
+		namespaceLabel model.LabelName
+		appLabel       model.LabelName
+		set            func(s *CircuitBreakerStatus, value float64)
+	}{
+		{
+			query:          query(""),
+			namespaceLabel: "source_workload_namespace",
+			appLabel:       "source_app",
+			set:            func(s *CircuitBreakerStatus, value float64) { s.RequestRate = value },
+		},
+		{
+			query:          query(fmt.Sprintf(`,response_flags=~".*%s.*"`, responseFlagUpstreamOverflow)),
+			namespaceLabel: "source_workload_namespace",
+			appLabel:       "source_app",
+			set:            func(s *CircuitBreakerStatus, value float64) { s.TripRate = value },
+		},
+		{
+			query:          query(fmt.Sprintf(`,response_flags=~".*%s.*"`, responseFlagNoHealthyUpstream)),
+			namespaceLabel: "source_workload_namespace",
+			appLabel:       "source_app",
+			set:            func(s *CircuitBreakerStatus, value float64) { s.NoHealthyUpstreamRate = value },
+		},
+		{
+			query:          fmt.Sprintf("sum(rate(envoy_cluster_upstream_rq_pending_overflow{%s}[%s])) by (namespace, app)", clusterFilter, interval),
+			namespaceLabel: "namespace",
+			appLabel:       "app",
+			set:            func(s *CircuitBreakerStatus, value float64) { s.PendingOverflowRate = value },
+		},
+		{
+			query:          fmt.Sprintf("sum(envoy_cluster_outlier_detection_ejections_active{%s}) by (namespace, app)", clusterFilter),
+			namespaceLabel: "namespace",
+			appLabel:       "app",
+			set:            func(s *CircuitBreakerStatus, value float64) { s.EjectedHosts = value },
+		},
+	} {
+		vector, err := common.QueryPrometheusVector(ctx, api, q.query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sample := range vector {
+			source := fmt.Sprintf("%s/%s", sample.Metric[q.namespaceLabel], sample.Metric[q.appLabel])
+			if _, ok := statuses[source]; !ok {
+				statuses[source] = &CircuitBreakerStatus{
+					Source: source,
+				}
+			}
+			q.set(statuses[source], common.Round(float64(sample.Value)))
+		}
+	}
+
+	data := make([]CircuitBreakerStatus, 0, len(statuses))
+	for _, s := range statuses {
+		if s.RequestRate > 0 {
+			s.TripPercentage = common.Round(s.TripRate / s.RequestRate * 100)
+		}
+		data = append(data, *s)
+	}
+	sort.Slice(data, func(i, j int) bool {
+		return data[i].Source < data[j].Source
+	})
+
+	return data, nil
+}
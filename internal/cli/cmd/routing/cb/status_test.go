@@ -0,0 +1,72 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cb
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common/commontest"
+)
+
+func TestGetCircuitBreakerStatuses(t *testing.T) {
+	source := func(app string) []string {
+		return []string{"source_workload_namespace", "backyards-demo", "source_app", app}
+	}
+	proxy := func(app string) []string {
+		return []string{"namespace", "backyards-demo", "app", app}
+	}
+
+	promAPI := &commontest.FakePrometheusAPI{
+		Results: []commontest.PrometheusResult{
+			{Substr: `response_flags=~".*UO.*"`, Vector: model.Vector{commontest.Sample(4, source("payments")...)}},
+			{Substr: `response_flags=~".*UH.*"`, Vector: model.Vector{commontest.Sample(1, source("payments")...)}},
+			{Substr: "istio_requests_total", Vector: model.Vector{
+				commontest.Sample(10, source("payments")...),
+				commontest.Sample(2, source("analytics")...),
+			}},
+			{Substr: "envoy_cluster_upstream_rq_pending_overflow", Vector: model.Vector{commontest.Sample(3, proxy("payments")...)}},
+			{Substr: "envoy_cluster_outlier_detection_ejections_active", Vector: model.Vector{commontest.Sample(1, proxy("analytics")...)}},
+		},
+	}
+
+	statuses, err := getCircuitBreakerStatuses(context.Background(), promAPI,
+		types.NamespacedName{Name: "notifications", Namespace: "backyards-demo"}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []CircuitBreakerStatus{
+		{Source: "backyards-demo/analytics", RequestRate: 2, EjectedHosts: 1},
+		{Source: "backyards-demo/payments", RequestRate: 10, TripRate: 4, TripPercentage: 40, PendingOverflowRate: 3, NoHealthyUpstreamRate: 1},
+	}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("unexpected statuses\ngot : %+v\nwant: %+v", statuses, expected)
+	}
+
+	for _, query := range promAPI.Queries {
+		if strings.Contains(query, "envoy_cluster_") {
+			if !strings.Contains(query, `upstream_cluster=~"outbound\\|[0-9]+\\|[^|]*\\|notifications\\.backyards-demo\\.svc\\.cluster\\.local"`) {
+				t.Errorf("unexpected cluster filter: %s", query)
+			}
+		}
+	}
+}
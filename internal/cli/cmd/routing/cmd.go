@@ -18,6 +18,10 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/cb"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/fault"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/lb"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/mirror"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/retry"
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/ts"
 	"github.com/banzaicloud/backyards-cli/pkg/cli"
 )
@@ -32,6 +36,13 @@ func NewRootCmd(cli cli.CLI) *cobra.Command {
 	cmd.AddCommand(
 		ts.NewRootCmd(cli),
 		cb.NewRootCmd(cli),
+		lb.NewRootCmd(cli),
+		mirror.NewRootCmd(cli),
+		fault.NewRootCmd(cli),
+		retry.NewRootCmd(cli),
+		newListCommand(cli),
+		newExportCommand(cli),
+		newApplyCommand(cli),
 	)
 
 	return cmd
@@ -16,23 +16,27 @@ package common
 
 import (
 	"context"
+	"encoding/json"
+	"math"
 	"regexp"
 	"strings"
+	"time"
 
 	"emperror.dev/errors"
-	"github.com/spf13/viper"
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"knative.dev/pkg/apis/istio/v1alpha3"
 
 	"github.com/banzaicloud/backyards-cli/pkg/cli"
-	"github.com/banzaicloud/backyards-cli/pkg/graphql"
-	"github.com/banzaicloud/backyards-cli/pkg/k8s"
 )
 
 const (
-	backyardsServiceAccountName        = "backyards"
-	dns1123LabelFmt             string = "[a-z0-9]([-a-z0-9]*[a-z0-9])?"
+	dns1123LabelFmt     string = "[a-z0-9]([-a-z0-9]*[a-z0-9])?"
+	clusterDomainSuffix        = ".svc.cluster.local"
 )
 
 var dns1123LabelRegexp = regexp.MustCompile("^" + dns1123LabelFmt + "$")
@@ -55,6 +59,46 @@ func ParseServiceID(serviceID string) (types.NamespacedName, error) {
 	}, nil
 }
 
+// GetFQDN returns the fully qualified name of a host, short names are interpreted relative to the namespace
+func GetFQDN(host, namespace string) string {
+	if strings.HasSuffix(host, clusterDomainSuffix) || strings.Contains(host, "*") {
+		return host
+	}
+
+	switch strings.Count(host, ".") {
+	case 0:
+		return host + "." + namespace + clusterDomainSuffix
+	case 1:
+		return host + clusterDomainSuffix
+	default:
+		return host
+	}
+}
+
+// GetServiceNameFromHost returns the name of the service a host refers to, short names are interpreted relative
+// to the namespace. It returns false if the host is not the name of a service in the cluster, e.g. an external host.
+func GetServiceNameFromHost(host, namespace string) (types.NamespacedName, bool) {
+	name := GetFQDN(host, namespace)
+	switch {
+	case strings.HasSuffix(name, clusterDomainSuffix):
+		name = strings.TrimSuffix(name, clusterDomainSuffix)
+	case strings.HasSuffix(name, ".svc"):
+		name = strings.TrimSuffix(name, ".svc")
+	default:
+		return types.NamespacedName{}, false
+	}
+
+	parts := strings.Split(name, ".")
+	if len(parts) != 2 || !dns1123LabelRegexp.MatchString(parts[0]) || !dns1123LabelRegexp.MatchString(parts[1]) {
+		return types.NamespacedName{}, false
+	}
+
+	return types.NamespacedName{
+		Namespace: parts[1],
+		Name:      parts[0],
+	}, true
+}
+
 func GetServiceByName(cli cli.CLI, serviceName types.NamespacedName) (*corev1.Service, error) {
 	var service corev1.Service
 
@@ -103,32 +147,105 @@ func GetVirtualserviceByName(cli cli.CLI, serviceName types.NamespacedName) (*v1
 	return &vservice, nil
 }
 
-func GetGraphQLClient(cli cli.CLI) (graphql.Client, error) {
+// GetVirtualserviceHTTPRoutes decodes the HTTP routes of a virtual service into the given slice,
+// it makes possible to read fields which are missing from the typed VirtualService definition
+func GetVirtualserviceHTTPRoutes(cli cli.CLI, serviceName types.NamespacedName, routes interface{}) error {
 	k8sclient, err := cli.GetK8sClient()
+	if err != nil {
+		return err
+	}
+
+	vservice := &unstructured.Unstructured{}
+	vservice.SetGroupVersionKind(v1alpha3.SchemeGroupVersion.WithKind("VirtualService"))
+	err = k8sclient.Get(context.Background(), serviceName, vservice)
+	if err != nil {
+		return errors.WrapIf(err, "could not get virtual service")
+	}
+
+	httpRoutes, _, err := unstructured.NestedSlice(vservice.Object, "spec", "http")
+	if err != nil {
+		return errors.WrapIf(err, "could not get http routes of virtual service")
+	}
+
+	raw, err := json.Marshal(httpRoutes)
+	if err != nil {
+		return errors.WrapIf(err, "could not marshal http routes")
+	}
+
+	err = json.Unmarshal(raw, routes)
+	if err != nil {
+		return errors.WrapIf(err, "could not unmarshal http routes")
+	}
+
+	return nil
+}
+
+// GetPrometheusAPI returns a client for the Prometheus of Backyards, which is reachable through the Backyards endpoint
+func GetPrometheusAPI(cli cli.CLI) (promv1.API, error) {
+	endpoint, err := cli.GetEndpoint(0)
 	if err != nil {
 		return nil, err
 	}
 
-	token, err := k8s.GetTokenForServiceAccountName(k8sclient, types.NamespacedName{
-		Name:      backyardsServiceAccountName,
-		Namespace: viper.GetString("backyards.namespace"),
+	client, err := promapi.NewClient(promapi.Config{
+		Address:      endpoint.URLForPath("/prometheus"),
+		RoundTripper: endpoint.HTTPClient().Transport,
 	})
 	if err != nil {
-		return nil, err
+		return nil, errors.WrapIf(err, "could not create prometheus client")
+	}
+
+	return promv1.NewAPI(client), nil
+}
+
+// Round rounds a metric value to 3 decimals for display, NaN and infinite values are shown as 0
+func Round(value float64) float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0
 	}
 
-	pf, err := cli.GetPortforwardForIGW(0)
+	return math.Round(value*1000) / 1000
+}
+
+// QueryPrometheusVector runs an instant query which is expected to return an instant vector
+func QueryPrometheusVector(ctx context.Context, api promv1.API, query string) (model.Vector, error) {
+	value, _, err := api.Query(ctx, query, time.Now())
 	if err != nil {
-		return nil, err
+		return nil, errors.WrapIfWithDetails(err, "could not run prometheus query", "query", query)
+	}
+
+	vector, ok := value.(model.Vector)
+	if !ok {
+		return nil, errors.NewWithDetails("unexpected prometheus query result type", "type", value.Type().String())
 	}
 
-	err = pf.Run()
+	return vector, nil
+}
+
+// QueryPrometheusScalar runs an instant query which is expected to return a single sample,
+// the second return value is false if the query returned no data
+func QueryPrometheusScalar(ctx context.Context, api promv1.API, query string) (float64, bool, error) {
+	value, _, err := api.Query(ctx, query, time.Now())
 	if err != nil {
-		return nil, err
+		return 0, false, errors.WrapIfWithDetails(err, "could not run prometheus query", "query", query)
 	}
 
-	client := graphql.NewClient(pf.GetURL("/api/graphql"))
-	client.SetJWTToken(token)
+	var sample model.SampleValue
+	switch v := value.(type) {
+	case model.Vector:
+		if len(v) == 0 {
+			return 0, false, nil
+		}
+		sample = v[0].Value
+	case *model.Scalar:
+		sample = v.Value
+	default:
+		return 0, false, errors.NewWithDetails("unexpected prometheus query result type", "type", value.Type().String())
+	}
+
+	if math.IsNaN(float64(sample)) {
+		return 0, false, nil
+	}
 
-	return client, nil
+	return float64(sample), true, nil
 }
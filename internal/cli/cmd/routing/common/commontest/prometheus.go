@@ -0,0 +1,64 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commontest
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// PrometheusResult is returned for the queries which contain Substr
+type PrometheusResult struct {
+	Substr string
+	Vector model.Vector
+}
+
+// FakePrometheusAPI answers instant queries with the first matching result, or with an empty vector
+type FakePrometheusAPI struct {
+	promv1.API
+
+	Results []PrometheusResult
+	Queries []string
+}
+
+func (a *FakePrometheusAPI) Query(ctx context.Context, query string, ts time.Time) (model.Value, api.Warnings, error) {
+	a.Queries = append(a.Queries, query)
+
+	for _, result := range a.Results {
+		if strings.Contains(query, result.Substr) {
+			return result.Vector, nil, nil
+		}
+	}
+
+	return model.Vector{}, nil, nil
+}
+
+// Sample returns a sample with the given labels
+func Sample(value float64, labels ...string) *model.Sample {
+	metric := make(model.Metric, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		metric[model.LabelName(labels[i])] = model.LabelValue(labels[i+1])
+	}
+
+	return &model.Sample{
+		Metric: metric,
+		Value:  model.SampleValue(value),
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	"knative.dev/pkg/apis/istio/common/v1alpha1"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+)
+
+const (
+	matchFieldURI         = "uri"
+	matchFieldScheme      = "scheme"
+	matchFieldMethod      = "method"
+	matchFieldAuthority   = "authority"
+	matchFieldPort        = "port"
+	matchFieldHeader      = "header"
+	matchFieldSourceLabel = "sourcelabel"
+
+	stringMatchExact  = "exact"
+	stringMatchPrefix = "prefix"
+	stringMatchSuffix = "suffix"
+	stringMatchRegex  = "regex"
+)
+
+const MatchFlagUsage = "Match condition of the route in <field>=[exact|prefix|suffix|regex:]<value> format, " +
+	"where field is one of uri, scheme, method, authority, port, header.<name> or sourcelabel.<name> " +
+	"(can be repeated, every condition must match)"
+
+// conditionStartRegexp matches the beginning of a match condition, values may contain commas, e.g. regex:[0-9]{1,3}
+var conditionStartRegexp = regexp.MustCompile(`^((uri|scheme|method|authority|port)|(header|sourcelabel)\.[^=]+)=`)
+
+// Matches holds the formatted conditions of match requests, the conditions of a single request are separated by commas
+type Matches []string
+
+func (m Matches) String() string {
+	if len(m) == 0 {
+		return "-"
+	}
+
+	return strings.Join(m, " or ")
+}
+
+// ParseHTTPMatchRequests parses match conditions in <field>=[<type>:]<value> format into match requests
+func ParseHTTPMatchRequests(conditions []string) ([]v1alpha3.HTTPMatchRequest, error) {
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	var match v1alpha3.HTTPMatchRequest
+	for _, condition := range conditions {
+		err := parseMatchCondition(&match, condition)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return []v1alpha3.HTTPMatchRequest{match}, nil
+}
+
+// FormatHTTPMatchRequest returns the conditions of a match request in the same format as ParseHTTPMatchRequests accepts
+func FormatHTTPMatchRequest(match v1alpha3.HTTPMatchRequest) []string {
+	conditions := make([]string, 0)
+
+	for _, f := range []struct {
+		name  string
+		match *v1alpha1.StringMatch
+	}{
+		{matchFieldURI, match.URI},
+		{matchFieldScheme, match.Scheme},
+		{matchFieldMethod, match.Method},
+		{matchFieldAuthority, match.Authority},
+	} {
+		if f.match != nil {
+			conditions = append(conditions, fmt.Sprintf("%s=%s", f.name, formatStringMatch(*f.match)))
+		}
+	}
+
+	if match.Port > 0 {
+		conditions = append(conditions, fmt.Sprintf("%s=%d", matchFieldPort, match.Port))
+	}
+
+	headers := make([]string, 0)
+	for name, m := range match.Headers {
+		headers = append(headers, fmt.Sprintf("%s.%s=%s", matchFieldHeader, name, formatStringMatch(m)))
+	}
+	sort.Strings(headers)
+
+	labels := make([]string, 0)
+	for name, value := range match.SourceLabels {
+		labels = append(labels, fmt.Sprintf("%s.%s=%s", matchFieldSourceLabel, name, value))
+	}
+	sort.Strings(labels)
+
+	return append(append(conditions, headers...), labels...)
+}
+
+// FormatHTTPMatchRequests returns the formatted conditions of every match request
+func FormatHTTPMatchRequests(matchRequests []v1alpha3.HTTPMatchRequest) Matches {
+	m := make(Matches, 0, len(matchRequests))
+	for _, match := range matchRequests {
+		m = append(m, strings.Join(FormatHTTPMatchRequest(match), ","))
+	}
+
+	return m
+}
+
+// Parse parses the formatted conditions back into match requests
+func (m Matches) Parse() ([]v1alpha3.HTTPMatchRequest, error) {
+	matchRequests := make([]v1alpha3.HTTPMatchRequest, 0, len(m))
+	for _, conditions := range m {
+		match, err := ParseHTTPMatchRequests(splitConditions(conditions))
+		if err != nil {
+			return nil, err
+		}
+		matchRequests = append(matchRequests, match...)
+	}
+
+	if len(matchRequests) == 0 {
+		return nil, nil
+	}
+
+	return matchRequests, nil
+}
+
+// splitConditions splits the conditions of a single match request at the commas which start a new condition
+func splitConditions(conditions string) []string {
+	parts := make([]string, 0)
+	for _, part := range strings.Split(conditions, ",") {
+		if len(parts) > 0 && !conditionStartRegexp.MatchString(part) {
+			parts[len(parts)-1] += "," + part
+			continue
+		}
+		parts = append(parts, part)
+	}
+
+	return parts
+}
+
+func parseMatchCondition(match *v1alpha3.HTTPMatchRequest, condition string) error {
+	parts := strings.SplitN(condition, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return errors.Errorf("invalid match condition: '%s': format must be <field>=[<type>:]<value>", condition)
+	}
+
+	field, value := parts[0], parts[1]
+	var key string
+	if p := strings.SplitN(field, ".", 2); len(p) == 2 {
+		field, key = p[0], p[1]
+	}
+
+	duplicate := func() error {
+		return errors.Errorf("invalid match condition: '%s': '%s' is already matched by another condition", condition, parts[0])
+	}
+
+	switch field {
+	case matchFieldURI:
+		if match.URI != nil {
+			return duplicate()
+		}
+		match.URI = parseStringMatch(value)
+	case matchFieldScheme:
+		if match.Scheme != nil {
+			return duplicate()
+		}
+		match.Scheme = parseStringMatch(value)
+	case matchFieldMethod:
+		if match.Method != nil {
+			return duplicate()
+		}
+		match.Method = parseStringMatch(value)
+	case matchFieldAuthority:
+		if match.Authority != nil {
+			return duplicate()
+		}
+		match.Authority = parseStringMatch(value)
+	case matchFieldPort:
+		if match.Port > 0 {
+			return duplicate()
+		}
+		port, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return errors.Errorf("invalid match condition: '%s': port must be a number", condition)
+		}
+		match.Port = uint32(port)
+	case matchFieldHeader:
+		if key == "" {
+			return errors.Errorf("invalid match condition: '%s': format must be header.<name>=[<type>:]<value>", condition)
+		}
+		if _, ok := match.Headers[key]; ok {
+			return duplicate()
+		}
+		if match.Headers == nil {
+			match.Headers = make(map[string]v1alpha1.StringMatch)
+		}
+		match.Headers[key] = *parseStringMatch(value)
+	case matchFieldSourceLabel:
+		if key == "" {
+			return errors.Errorf("invalid match condition: '%s': format must be sourcelabel.<name>=<value>", condition)
+		}
+		if _, ok := match.SourceLabels[key]; ok {
+			return duplicate()
+		}
+		if match.SourceLabels == nil {
+			match.SourceLabels = make(map[string]string)
+		}
+		match.SourceLabels[key] = value
+	default:
+		return errors.Errorf("invalid match condition: '%s': unknown field '%s'", condition, field)
+	}
+
+	if key != "" && field != matchFieldHeader && field != matchFieldSourceLabel {
+		return errors.Errorf("invalid match condition: '%s': field '%s' does not have keys", condition, field)
+	}
+
+	return nil
+}
+
+func parseStringMatch(value string) *v1alpha1.StringMatch {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) == 2 {
+		switch parts[0] {
+		case stringMatchExact:
+			return &v1alpha1.StringMatch{Exact: parts[1]}
+		case stringMatchPrefix:
+			return &v1alpha1.StringMatch{Prefix: parts[1]}
+		case stringMatchSuffix:
+			return &v1alpha1.StringMatch{Suffix: parts[1]}
+		case stringMatchRegex:
+			return &v1alpha1.StringMatch{Regex: parts[1]}
+		}
+	}
+
+	return &v1alpha1.StringMatch{Exact: value}
+}
+
+func formatStringMatch(match v1alpha1.StringMatch) string {
+	switch {
+	case match.Prefix != "":
+		return stringMatchPrefix + ":" + match.Prefix
+	case match.Suffix != "":
+		return stringMatchSuffix + ":" + match.Suffix
+	case match.Regex != "":
+		return stringMatchRegex + ":" + match.Regex
+	default:
+		return stringMatchExact + ":" + match.Exact
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHTTPMatchRequests(t *testing.T) {
+	tests := map[string]struct {
+		conditions []string
+		expected   []string
+		err        bool
+	}{
+		"empty": {
+			conditions: []string{},
+			expected:   nil,
+		},
+		"header and uri": {
+			conditions: []string{"header.x-canary=true", "uri=prefix:/api"},
+			expected:   []string{"uri=prefix:/api", "header.x-canary=exact:true"},
+		},
+		"every field": {
+			conditions: []string{"sourcelabel.app=frontpage", "method=GET", "authority=regex:.*:8080", "scheme=exact:https", "port=8080", "uri=suffix:.json"},
+			expected:   []string{"uri=suffix:.json", "scheme=exact:https", "method=exact:GET", "authority=regex:.*:8080", "port=8080", "sourcelabel.app=frontpage"},
+		},
+		"value with colon": {
+			conditions: []string{"header.x-forwarded-host=example.com:80"},
+			expected:   []string{"header.x-forwarded-host=exact:example.com:80"},
+		},
+		"missing value": {
+			conditions: []string{"uri="},
+			err:        true,
+		},
+		"unknown field": {
+			conditions: []string{"path=/api"},
+			err:        true,
+		},
+		"header without name": {
+			conditions: []string{"header=true"},
+			err:        true,
+		},
+		"invalid port": {
+			conditions: []string{"port=http"},
+			err:        true,
+		},
+		"key on keyless field": {
+			conditions: []string{"uri.foo=/api"},
+			err:        true,
+		},
+		"duplicate field": {
+			conditions: []string{"uri=prefix:/api", "uri=prefix:/v1"},
+			err:        true,
+		},
+		"duplicate header": {
+			conditions: []string{"header.x-user=admin", "header.x-user=prefix:test"},
+			err:        true,
+		},
+		"duplicate source label": {
+			conditions: []string{"sourcelabel.app=frontpage", "sourcelabel.app=backend"},
+			err:        true,
+		},
+		"different headers": {
+			conditions: []string{"header.x-user=admin", "header.x-canary=true"},
+			expected:   []string{"header.x-canary=exact:true", "header.x-user=exact:admin"},
+		},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+
+		t.Run(name, func(t *testing.T) {
+			matches, err := ParseHTTPMatchRequests(test.conditions)
+			if test.err {
+				if err == nil {
+					t.Fatalf("expected error for %v", test.conditions)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var got []string
+			for _, match := range matches {
+				got = append(got, FormatHTTPMatchRequest(match)...)
+			}
+
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("unexpected match conditions\ngot : %q\nwant: %q", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestMatchesParse(t *testing.T) {
+	m := Matches{"uri=prefix:/api,header.x-canary=exact:true", "method=exact:POST", "uri=regex:/movies/[0-9]{1,3},header.x-ids=regex:[0-9]+(,[0-9]+)*"}
+
+	matches, err := m.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got := FormatHTTPMatchRequests(matches); !reflect.DeepEqual(got, m) {
+		t.Errorf("unexpected matches\ngot : %q\nwant: %q", got, m)
+	}
+}
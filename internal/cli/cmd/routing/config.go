@@ -0,0 +1,237 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"context"
+	"sort"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/cb"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/ts"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+)
+
+const routingConfigVersion = "v1alpha1"
+
+// RoutingConfig is the declarative routing configuration of a set of services
+type RoutingConfig struct {
+	Version  string                 `json:"version"`
+	Services []ServiceRoutingConfig `json:"services"`
+}
+
+// ServiceRoutingConfig holds the traffic shifting and circuit breaker settings of a single service
+type ServiceRoutingConfig struct {
+	Service         string                     `json:"service"`
+	TrafficShifting []ts.TrafficShiftingRule   `json:"trafficShifting,omitempty"`
+	CircuitBreaker  *cb.CircuitBreakerSettings `json:"circuitBreaker,omitempty"`
+}
+
+func (c ServiceRoutingConfig) IsEmpty() bool {
+	return len(c.TrafficShifting) == 0 && c.CircuitBreaker == nil
+}
+
+// Validate checks the config and normalizes the match conditions of the traffic shifting rules
+func (c *RoutingConfig) Validate() error {
+	if c.Version != routingConfigVersion {
+		return errors.Errorf("unsupported routing config version: '%s': must be %s", c.Version, routingConfigVersion)
+	}
+
+	services := make(map[types.NamespacedName]bool)
+	for i := range c.Services {
+		s := &c.Services[i]
+
+		serviceName, err := common.ParseServiceID(s.Service)
+		if err != nil {
+			return err
+		}
+		if services[serviceName] {
+			return errors.Errorf("service '%s' is specified more than once", serviceName)
+		}
+		services[serviceName] = true
+
+		matches := make(map[string]bool)
+		for j := range s.TrafficShifting {
+			rule := &s.TrafficShifting[j]
+
+			match, err := rule.Matches.Parse()
+			if err != nil {
+				return errors.WrapIff(err, "invalid traffic shifting rule for '%s'", serviceName)
+			}
+			rule.Matches = common.FormatHTTPMatchRequests(match)
+
+			if matches[rule.Matches.String()] {
+				return errors.Errorf("invalid traffic shifting rule for '%s': matches %s are specified more than once", serviceName, rule.Matches)
+			}
+			matches[rule.Matches.String()] = true
+
+			err = rule.Weights.Validate()
+			if err != nil {
+				return errors.WrapIff(err, "invalid traffic shifting rule for '%s'", serviceName)
+			}
+		}
+	}
+
+	return nil
+}
+
+// getServiceRoutingConfig returns the current routing config of a service
+func getServiceRoutingConfig(cli cli.CLI, serviceName types.NamespacedName) (*ServiceRoutingConfig, error) {
+	config := &ServiceRoutingConfig{
+		Service: serviceName.String(),
+	}
+
+	vservice, err := common.GetVirtualserviceByName(cli, serviceName)
+	if err != nil && !k8serrors.IsNotFound(errors.Cause(err)) {
+		return nil, errors.WrapIf(err, "could not get virtual service")
+	}
+	if err == nil {
+		config.TrafficShifting = ts.GetTrafficShiftingRules(vservice)
+	}
+
+	drule, err := common.GetDestinationRuleByName(cli, serviceName)
+	if err != nil && !k8serrors.IsNotFound(errors.Cause(err)) {
+		return nil, errors.WrapIf(err, "could not get destination rule")
+	}
+	if err == nil {
+		config.CircuitBreaker = cb.GetCircuitBreakerSettings(drule.Spec.TrafficPolicy)
+	}
+
+	return config, nil
+}
+
+// getServiceRoutingConfigs returns the routing config of every service with routing rules in a namespace,
+// or in every namespace if the namespace is empty. Only the virtual services and destination rules which are
+// named after an existing service and have it as their host are taken into account, since the routing rules
+// are managed per service, others (e.g. the ones of gateways) are left out.
+func getServiceRoutingConfigs(cli cli.CLI, namespace string) ([]ServiceRoutingConfig, error) {
+	var err error
+
+	cl, err := cli.GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+
+	listOpts := make([]client.ListOptionFunc, 0)
+	if namespace != "" {
+		listOpts = append(listOpts, client.InNamespace(namespace))
+	}
+
+	var vservices v1alpha3.VirtualServiceList
+	err = cl.List(context.Background(), &vservices, listOpts...)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list virtual services")
+	}
+
+	var drules v1alpha3.DestinationRuleList
+	err = cl.List(context.Background(), &drules, listOpts...)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list destination rules")
+	}
+
+	var services corev1.ServiceList
+	err = cl.List(context.Background(), &services, listOpts...)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list services")
+	}
+
+	existing := make(map[types.NamespacedName]bool)
+	for _, service := range services.Items {
+		existing[types.NamespacedName{Namespace: service.Namespace, Name: service.Name}] = true
+	}
+	getServiceName := func(meta metav1.ObjectMeta, hosts ...string) (types.NamespacedName, bool) {
+		serviceName := types.NamespacedName{Namespace: meta.Namespace, Name: meta.Name}
+		if !existing[serviceName] {
+			return types.NamespacedName{}, false
+		}
+		for _, host := range hosts {
+			if h, ok := common.GetServiceNameFromHost(host, meta.Namespace); ok && h == serviceName {
+				return serviceName, true
+			}
+		}
+		return types.NamespacedName{}, false
+	}
+
+	configs := make(map[types.NamespacedName]*ServiceRoutingConfig)
+	getConfig := func(serviceName types.NamespacedName) *ServiceRoutingConfig {
+		if _, ok := configs[serviceName]; !ok {
+			configs[serviceName] = &ServiceRoutingConfig{
+				Service: serviceName.String(),
+			}
+		}
+		return configs[serviceName]
+	}
+
+	for i := range vservices.Items {
+		vservice := &vservices.Items[i]
+		if !isMeshVirtualService(vservice) {
+			continue
+		}
+		serviceName, ok := getServiceName(vservice.ObjectMeta, vservice.Spec.Hosts...)
+		if !ok {
+			continue
+		}
+		rules := ts.GetTrafficShiftingRules(vservice)
+		if len(rules) == 0 {
+			continue
+		}
+		getConfig(serviceName).TrafficShifting = rules
+	}
+
+	for _, drule := range drules.Items {
+		serviceName, ok := getServiceName(drule.ObjectMeta, drule.Spec.Host)
+		if !ok {
+			continue
+		}
+		settings := cb.GetCircuitBreakerSettings(drule.Spec.TrafficPolicy)
+		if settings == nil {
+			continue
+		}
+		getConfig(serviceName).CircuitBreaker = settings
+	}
+
+	result := make([]ServiceRoutingConfig, 0, len(configs))
+	for _, config := range configs {
+		result = append(result, *config)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Service < result[j].Service
+	})
+
+	return result, nil
+}
+
+// isMeshVirtualService returns whether the virtual service applies to the sidecars of the mesh
+func isMeshVirtualService(vservice *v1alpha3.VirtualService) bool {
+	if len(vservice.Spec.Gateways) == 0 {
+		return true
+	}
+
+	for _, gateway := range vservice.Spec.Gateways {
+		if gateway == "mesh" {
+			return true
+		}
+	}
+
+	return false
+}
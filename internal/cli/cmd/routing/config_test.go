@@ -0,0 +1,103 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"knative.dev/pkg/apis/istio/common/v1alpha1"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+	"sigs.k8s.io/yaml"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/ts"
+)
+
+func TestExportedRoutingConfigCanBeApplied(t *testing.T) {
+	destination := func(subset string, weight int) v1alpha3.HTTPRouteDestination {
+		return v1alpha3.HTTPRouteDestination{
+			Destination: v1alpha3.Destination{Host: "movies", Subset: subset},
+			Weight:      weight,
+		}
+	}
+
+	tests := map[string]struct {
+		routes    []v1alpha3.HTTPRoute
+		wantRules int
+	}{
+		"plain route": {
+			routes: []v1alpha3.HTTPRoute{{Route: []v1alpha3.HTTPRouteDestination{destination("", 0)}}},
+		},
+		"single subset without weight": {
+			routes:    []v1alpha3.HTTPRoute{{Route: []v1alpha3.HTTPRouteDestination{destination("v1", 0)}}},
+			wantRules: 1,
+		},
+		"weighted subsets": {
+			routes: []v1alpha3.HTTPRoute{
+				{
+					Match: []v1alpha3.HTTPMatchRequest{{URI: &v1alpha1.StringMatch{Regex: "/movies/[0-9]{1,3}"}}},
+					Route: []v1alpha3.HTTPRouteDestination{destination("v3", 100)},
+				},
+				{Route: []v1alpha3.HTTPRouteDestination{destination("v1", 50), destination("v2", 50)}},
+			},
+			wantRules: 2,
+		},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+
+		t.Run(name, func(t *testing.T) {
+			vservice := &v1alpha3.VirtualService{Spec: v1alpha3.VirtualServiceSpec{HTTP: test.routes}}
+
+			rules := ts.GetTrafficShiftingRules(vservice)
+			if len(rules) != test.wantRules {
+				t.Fatalf("expected %d traffic shifting rules, got %d", test.wantRules, len(rules))
+			}
+
+			config := RoutingConfig{
+				Version: routingConfigVersion,
+				Services: []ServiceRoutingConfig{{
+					Service:         "backyards-demo/movies",
+					TrafficShifting: rules,
+				}},
+			}
+			data, err := yaml.Marshal(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			f, err := ioutil.TempFile("", "routing")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(f.Name())
+			_, err = f.Write(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+
+			applied, err := readRoutingConfig(f.Name())
+			if err != nil {
+				t.Fatalf("exported config is rejected: %s\n%s", err, data)
+			}
+			if len(applied.Services[0].TrafficShifting) != test.wantRules {
+				t.Fatalf("expected %d traffic shifting rules after apply, got %d", test.wantRules, len(applied.Services[0].TrafficShifting))
+			}
+		})
+	}
+}
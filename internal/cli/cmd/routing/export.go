@@ -0,0 +1,140 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"emperror.dev/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+	"github.com/banzaicloud/backyards-cli/pkg/output"
+)
+
+type exportCommand struct{}
+
+type exportOptions struct {
+	serviceIDs    []string
+	allNamespaces bool
+
+	namespace    string
+	serviceNames []types.NamespacedName
+}
+
+func newExportOptions() *exportOptions {
+	return &exportOptions{}
+}
+
+func newExportCommand(cli cli.CLI) *cobra.Command {
+	c := &exportCommand{}
+	options := newExportOptions()
+
+	cmd := &cobra.Command{
+		Use:   "export [namespace/servicename ...]",
+		Short: "Export traffic shifting and circuit breaker rules as a routing config file",
+		Long: `Export traffic shifting and circuit breaker rules as a routing config file.
+
+Without arguments every service with routing rules in the current namespace is exported,
+the output can be applied later with the 'routing apply' command.`,
+		Args:          cobra.ArbitraryArgs,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			options.serviceIDs = args
+
+			if len(options.serviceIDs) > 0 && options.allNamespaces {
+				return errors.New("services cannot be specified together with --all-namespaces")
+			}
+
+			for _, serviceID := range options.serviceIDs {
+				serviceName, err := common.ParseServiceID(serviceID)
+				if err != nil {
+					return err
+				}
+				options.serviceNames = append(options.serviceNames, serviceName)
+			}
+
+			if len(options.serviceNames) == 0 && !options.allNamespaces {
+				options.namespace, err = k8sclient.GetNamespaceWithContext(viper.GetString("kubeconfig"), viper.GetString("kubecontext"))
+				if err != nil {
+					return errors.WrapIf(err, "could not get namespace from kubeconfig")
+				}
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&options.allNamespaces, "all-namespaces", "A", options.allNamespaces, "Export routing rules across all namespaces")
+
+	return cmd
+}
+
+func (c *exportCommand) run(cli cli.CLI, options *exportOptions) error {
+	var err error
+
+	config := RoutingConfig{
+		Version:  routingConfigVersion,
+		Services: make([]ServiceRoutingConfig, 0),
+	}
+
+	if len(options.serviceNames) > 0 {
+		for _, serviceName := range options.serviceNames {
+			_, err = common.GetServiceByName(cli, serviceName)
+			if err != nil {
+				if k8serrors.IsNotFound(errors.Cause(err)) {
+					return err
+				}
+				return errors.WrapIf(err, "could not get service")
+			}
+
+			s, err := getServiceRoutingConfig(cli, serviceName)
+			if err != nil {
+				return err
+			}
+			config.Services = append(config.Services, *s)
+		}
+	} else {
+		config.Services, err = getServiceRoutingConfigs(cli, options.namespace)
+		if err != nil {
+			return err
+		}
+	}
+
+	var data []byte
+	if cli.OutputFormat() == output.OutputFormatJSON {
+		data, err = json.MarshalIndent(config, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(config)
+	}
+	if err != nil {
+		return errors.WrapIf(err, "could not marshal routing config")
+	}
+
+	_, err = fmt.Fprint(cli.Out(), string(data))
+
+	return errors.WrapIf(err, "could not write routing config")
+}
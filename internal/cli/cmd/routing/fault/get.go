@@ -0,0 +1,153 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fault
+
+import (
+	"emperror.dev/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	clierrors "github.com/banzaicloud/backyards-cli/internal/errors"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+)
+
+type getCommand struct{}
+
+type getOptions struct {
+	serviceID string
+
+	serviceName types.NamespacedName
+}
+
+type FaultInjectionRule struct {
+	Matches common.Matches `json:"matches,omitempty" yaml:"matches,omitempty"`
+
+	FixedDelay      string `json:"fixedDelay,omitempty" yaml:"fixedDelay,omitempty"`
+	DelayPercentage int    `json:"delayPercentage,omitempty" yaml:"delayPercentage,omitempty"`
+
+	AbortHTTPStatus int `json:"abortHttpStatus,omitempty" yaml:"abortHttpStatus,omitempty"`
+	AbortPercentage int `json:"abortPercentage,omitempty" yaml:"abortPercentage,omitempty"`
+}
+
+func newGetOptions() *getOptions {
+	return &getOptions{}
+}
+
+func newGetCommand(cli cli.CLI) *cobra.Command {
+	c := &getCommand{}
+	options := newGetOptions()
+
+	cmd := &cobra.Command{
+		Use:           "get [[--service=]namespace/servicename]",
+		Short:         "Get fault injection rules for a service",
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			if len(args) > 0 {
+				options.serviceID = args[0]
+			}
+
+			if options.serviceID == "" {
+				return errors.New("service must be specified")
+			}
+
+			options.serviceName, err = common.ParseServiceID(options.serviceID)
+			if err != nil {
+				return err
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.serviceID, "service", "", "Service name")
+
+	return cmd
+}
+
+func getFaultInjectionRulesByServiceName(cli cli.CLI, serviceName types.NamespacedName) ([]FaultInjectionRule, error) {
+	var err error
+
+	_, err = common.GetServiceByName(cli, serviceName)
+	if err != nil {
+		if k8serrors.IsNotFound(errors.Cause(err)) {
+			return nil, err
+		}
+		return nil, errors.WrapIf(err, "could not get service")
+	}
+
+	vservice, err := common.GetVirtualserviceByName(cli, serviceName)
+	if err != nil {
+		if k8serrors.IsNotFound(errors.Cause(err)) {
+			return nil, clierrors.NotFoundError{}
+		}
+		return nil, errors.WrapIf(err, "could not get service")
+	}
+
+	rules := make([]FaultInjectionRule, 0)
+	for _, route := range vservice.Spec.HTTP {
+		if route.Fault == nil {
+			continue
+		}
+
+		rule := FaultInjectionRule{
+			Matches: common.FormatHTTPMatchRequests(route.Match),
+		}
+
+		if route.Fault.Delay != nil {
+			rule.FixedDelay = route.Fault.Delay.FixedDelay
+			rule.DelayPercentage = route.Fault.Delay.Percent
+		}
+
+		if route.Fault.Abort != nil {
+			rule.AbortHTTPStatus = route.Fault.Abort.HTTPStatus
+			rule.AbortPercentage = route.Fault.Abort.Percent
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if len(rules) == 0 {
+		return nil, clierrors.NotFoundError{}
+	}
+
+	return rules, nil
+}
+
+func (c *getCommand) run(cli cli.CLI, options *getOptions) error {
+	var err error
+
+	rules, err := getFaultInjectionRulesByServiceName(cli, options.serviceName)
+	if err != nil {
+		if clierrors.IsNotFound(err) {
+			log.Infof("no fault injection rules set for %s", options.serviceName)
+			return nil
+		}
+		return err
+	}
+
+	err = Output(cli, rules)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
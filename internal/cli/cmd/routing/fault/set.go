@@ -0,0 +1,205 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fault
+
+import (
+	"time"
+
+	"emperror.dev/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	clierrors "github.com/banzaicloud/backyards-cli/internal/errors"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+)
+
+type setCommand struct{}
+
+type setOptions struct {
+	serviceID       string
+	delay           time.Duration
+	delayPercentage int
+	abortHTTPStatus int
+	abortPercentage int
+	matches         []string
+
+	serviceName   types.NamespacedName
+	parsedMatches []v1alpha3.HTTPMatchRequest
+}
+
+func newSetOptions() *setOptions {
+	return &setOptions{
+		delayPercentage: 100,
+		abortPercentage: 100,
+	}
+}
+
+func newSetCommand(cli cli.CLI) *cobra.Command {
+	c := &setCommand{}
+	options := newSetOptions()
+
+	cmd := &cobra.Command{
+		Use:           "set [[--service=]namespace/servicename] [--delay=duration] [--abort-status=status]",
+		Short:         "Set fault injection rules for a service",
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			if len(args) > 0 {
+				options.serviceID = args[0]
+			}
+
+			if options.serviceID == "" {
+				return errors.New("service must be specified")
+			}
+
+			err = options.validate()
+			if err != nil {
+				return err
+			}
+
+			options.serviceName, err = common.ParseServiceID(options.serviceID)
+			if err != nil {
+				return err
+			}
+
+			options.parsedMatches, err = common.ParseHTTPMatchRequests(options.matches)
+			if err != nil {
+				return err
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.serviceID, "service", "", "Service name")
+
+	// Delay
+	flags.DurationVar(&options.delay, "delay", options.delay, "Fixed delay before forwarding the request")
+	flags.IntVar(&options.delayPercentage, "delay-percentage", options.delayPercentage, "Percentage of requests on which the delay will be injected")
+
+	// Abort
+	flags.IntVar(&options.abortHTTPStatus, "abort-status", options.abortHTTPStatus, "HTTP status code to use to abort the request")
+	flags.IntVar(&options.abortPercentage, "abort-percentage", options.abortPercentage, "Percentage of requests to be aborted with the given status code")
+
+	flags.StringArrayVar(&options.matches, "match", []string{}, common.MatchFlagUsage)
+
+	return cmd
+}
+
+func (o *setOptions) validate() error {
+	if o.delay == 0 && o.abortHTTPStatus == 0 {
+		return errors.New("at least one of delay or abort status must be specified")
+	}
+
+	if o.delay < 0 {
+		return errors.New("delay must be greater than 0")
+	}
+
+	if o.abortHTTPStatus != 0 && (o.abortHTTPStatus < 100 || o.abortHTTPStatus > 599) {
+		return errors.New("abort status must be a valid HTTP status code")
+	}
+
+	for _, percentage := range []int{o.delayPercentage, o.abortPercentage} {
+		if percentage < 0 || percentage > 100 {
+			return errors.New("percentage must be between 0 and 100")
+		}
+	}
+
+	return nil
+}
+
+func (c *setCommand) run(cli cli.CLI, options *setOptions) error {
+	var err error
+
+	service, err := common.GetServiceByName(cli, options.serviceName)
+	if err != nil {
+		if k8serrors.IsNotFound(errors.Cause(err)) {
+			return err
+		}
+		return errors.WrapIf(err, "could not get service")
+	}
+
+	client, err := cli.GetGraphQLClient()
+	if err != nil {
+		return errors.WrapIf(err, "could not get initialized graphql client")
+	}
+
+	fault := &v1alpha3.HTTPFaultInjection{}
+	if options.delay > 0 {
+		fault.Delay = &v1alpha3.InjectDelay{
+			FixedDelay: options.delay.String(),
+			Percent:    options.delayPercentage,
+		}
+	}
+	if options.abortHTTPStatus > 0 {
+		fault.Abort = &v1alpha3.InjectAbort{
+			HTTPStatus: options.abortHTTPStatus,
+			Percent:    options.abortPercentage,
+		}
+	}
+
+	req := graphql.ApplyHTTPRouteRequest{
+		Name:      service.Name,
+		Namespace: service.Namespace,
+		Match:     options.parsedMatches,
+		Fault:     fault,
+	}
+
+	r, err := client.ApplyHTTPRoute(cli.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	if !r {
+		return errors.New("unknown error: cannot set fault injection")
+	}
+
+	err = c.output(cli, options)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *setCommand) output(cli cli.CLI, options *setOptions) error {
+	rules, err := getFaultInjectionRulesByServiceName(cli, options.serviceName)
+	if err != nil {
+		if clierrors.IsNotFound(err) {
+			log.Infof("no fault injection rules set for %s", options.serviceName)
+			return nil
+		}
+		return err
+	}
+
+	if cli.InteractiveTerminal() {
+		log.Infof("fault injection rules successfully applied to %s", options.serviceName)
+	}
+
+	err = Output(cli, rules)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
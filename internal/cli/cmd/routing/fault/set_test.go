@@ -0,0 +1,105 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fault
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis/istio/common/v1alpha1"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli/clitest"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql/graphqltest"
+)
+
+func TestSetCommand(t *testing.T) {
+	clitest.MutationTests{
+		Field:      "applyHTTPRoute",
+		NewCommand: newSetCommand,
+		Objects:    []runtime.Object{clitest.NewDemoService()},
+		Cases: []clitest.MutationTestCase{
+			{
+				Name: "injects a delay",
+				Args: []string{"backyards-demo/movies", "--delay=5s", "--delay-percentage=30"},
+				WantInput: &graphql.ApplyHTTPRouteRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Fault: &v1alpha3.HTTPFaultInjection{
+						Delay: &v1alpha3.InjectDelay{FixedDelay: "5s", Percent: 30},
+					},
+				},
+			},
+			{
+				Name: "injects an abort for matching requests",
+				Args: []string{"backyards-demo/movies", "--abort-status=503", "--abort-percentage=10", "--match=uri=prefix:/api"},
+				WantInput: &graphql.ApplyHTTPRouteRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Match:     []v1alpha3.HTTPMatchRequest{{URI: &v1alpha1.StringMatch{Prefix: "/api"}}},
+					Fault: &v1alpha3.HTTPFaultInjection{
+						Abort: &v1alpha3.InjectAbort{HTTPStatus: 503, Percent: 10},
+					},
+				},
+			},
+			{
+				Name: "injects a delay and an abort",
+				Args: []string{"backyards-demo/movies", "--delay=1s", "--abort-status=500"},
+				WantInput: &graphql.ApplyHTTPRouteRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Fault: &v1alpha3.HTTPFaultInjection{
+						Delay: &v1alpha3.InjectDelay{FixedDelay: "1s", Percent: 100},
+						Abort: &v1alpha3.InjectAbort{HTTPStatus: 500, Percent: 100},
+					},
+				},
+			},
+			{
+				Name:    "requires a delay or an abort",
+				Args:    []string{"backyards-demo/movies"},
+				WantErr: true,
+			},
+			{
+				Name:    "rejects invalid status codes",
+				Args:    []string{"backyards-demo/movies", "--abort-status=600"},
+				WantErr: true,
+			},
+			{
+				Name:    "rejects invalid percentages",
+				Args:    []string{"backyards-demo/movies", "--delay=1s", "--delay-percentage=101"},
+				WantErr: true,
+			},
+			{
+				Name:    "fails for unknown services",
+				Args:    []string{"backyards-demo/books", "--delay=1s"},
+				WantErr: true,
+			},
+			{
+				Name:    "fails if the backend does not confirm the change",
+				Args:    []string{"backyards-demo/movies", "--delay=1s"},
+				Respond: func(s *graphqltest.Server) { s.Respond("applyHTTPRoute", false) },
+				WantErr: true,
+				WantInput: &graphql.ApplyHTTPRouteRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Fault: &v1alpha3.HTTPFaultInjection{
+						Delay: &v1alpha3.InjectDelay{FixedDelay: "1s", Percent: 100},
+					},
+				},
+			},
+		},
+	}.Run(t)
+}
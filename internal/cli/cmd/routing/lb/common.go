@@ -0,0 +1,94 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lb
+
+import (
+	"strings"
+
+	"emperror.dev/errors"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+)
+
+const (
+	policyConsistentHash = "CONSISTENT_HASH"
+
+	hashOnHeader   = "header"
+	hashOnCookie   = "cookie"
+	hashOnSourceIP = "source-ip"
+)
+
+var simplePolicies = []v1alpha3.SimpleLB{
+	v1alpha3.SimpleLBRoundRobin,
+	v1alpha3.SimpleLBLeastConn,
+	v1alpha3.SimpleLBRandom,
+	v1alpha3.SimpleLBPassthrough,
+}
+
+type LoadBalancerSettings struct {
+	Policy          string `json:"policy"`
+	HashOn          string `json:"hashOn,omitempty"`
+	CookiePath      string `json:"cookiePath,omitempty"`
+	CookieTTL       string `json:"cookieTTL,omitempty"`
+	MinimumRingSize uint64 `json:"minimumRingSize,omitempty"`
+}
+
+// GetLoadBalancerSettings returns the load balancer settings of a traffic policy, or nil if it is not set
+func GetLoadBalancerSettings(tp *v1alpha3.TrafficPolicy) *LoadBalancerSettings {
+	if tp == nil || tp.LoadBalancer == nil {
+		return nil
+	}
+
+	lb := tp.LoadBalancer
+	if lb.ConsistentHash == nil {
+		return &LoadBalancerSettings{
+			Policy: string(lb.Simple),
+		}
+	}
+
+	settings := &LoadBalancerSettings{
+		Policy:          policyConsistentHash,
+		MinimumRingSize: lb.ConsistentHash.MinimumRingSize,
+	}
+
+	switch {
+	case lb.ConsistentHash.HTTPHeaderName != "":
+		settings.HashOn = hashOnHeader + ":" + lb.ConsistentHash.HTTPHeaderName
+	case lb.ConsistentHash.HTTPCookie != nil:
+		settings.HashOn = hashOnCookie + ":" + lb.ConsistentHash.HTTPCookie.Name
+		settings.CookiePath = lb.ConsistentHash.HTTPCookie.Path
+		settings.CookieTTL = lb.ConsistentHash.HTTPCookie.TTL
+	case lb.ConsistentHash.UseSourceIP:
+		settings.HashOn = hashOnSourceIP
+	}
+
+	return settings
+}
+
+// parseSimplePolicy accepts the policy names in both ROUND_ROBIN and round-robin format
+func parseSimplePolicy(policy string) (v1alpha3.SimpleLB, error) {
+	normalized := strings.ToUpper(strings.Replace(policy, "-", "_", -1))
+	for _, p := range simplePolicies {
+		if string(p) == normalized {
+			return p, nil
+		}
+	}
+
+	names := make([]string, 0, len(simplePolicies))
+	for _, p := range simplePolicies {
+		names = append(names, strings.ToLower(strings.Replace(string(p), "_", "-", -1)))
+	}
+
+	return "", errors.Errorf("invalid load balancing policy: '%s': must be one of %s", policy, strings.Join(names, ", "))
+}
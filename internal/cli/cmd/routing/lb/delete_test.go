@@ -0,0 +1,74 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lb
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli/clitest"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql/graphqltest"
+)
+
+func TestDeleteCommand(t *testing.T) {
+	clitest.MutationTests{
+		Field:      "disableGlobalTrafficPolicy",
+		NewCommand: newDeleteCommand,
+		Objects:    []runtime.Object{clitest.NewDemoService()},
+		Cases: []clitest.MutationTestCase{
+			{
+				Name: "deletes the load balancer settings",
+				Args: []string{"backyards-demo/movies"},
+				WantInput: &graphql.DisableGlobalTrafficPolicyRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Rules:     []string{"LoadBalancer"},
+				},
+			},
+			{
+				Name: "accepts the service flag",
+				Args: []string{"--service=backyards-demo/movies"},
+				WantInput: &graphql.DisableGlobalTrafficPolicyRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Rules:     []string{"LoadBalancer"},
+				},
+			},
+			{
+				Name:    "fails if the backend does not confirm the deletion",
+				Args:    []string{"backyards-demo/movies"},
+				Respond: func(s *graphqltest.Server) { s.Respond("disableGlobalTrafficPolicy", false) },
+				WantErr: true,
+				WantInput: &graphql.DisableGlobalTrafficPolicyRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Rules:     []string{"LoadBalancer"},
+				},
+			},
+			{
+				Name:    "fails for unknown services",
+				Args:    []string{"backyards-demo/books"},
+				WantErr: true,
+			},
+			{
+				Name:    "requires a service",
+				Args:    []string{},
+				WantErr: true,
+			},
+		},
+	}.Run(t)
+}
@@ -0,0 +1,261 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lb
+
+import (
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/AlecAivazis/survey/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	clierrors "github.com/banzaicloud/backyards-cli/internal/errors"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+)
+
+const consistentHashOption = "consistent-hash"
+
+type setCommand struct{}
+
+type setOptions struct {
+	serviceID       string
+	policy          string
+	hashHeader      string
+	hashCookie      string
+	cookiePath      string
+	cookieTTL       time.Duration
+	cookieTTLSet    bool
+	hashSourceIP    bool
+	minimumRingSize uint64
+
+	serviceName  types.NamespacedName
+	loadBalancer *v1alpha3.LoadBalancerSettings
+}
+
+func newSetOptions() *setOptions {
+	return &setOptions{}
+}
+
+func newSetCommand(cli cli.CLI) *cobra.Command {
+	c := &setCommand{}
+	options := newSetOptions()
+
+	cmd := &cobra.Command{
+		Use:           "set [[--service=]namespace/servicename] [--policy=policy|--hash-header=name|--hash-cookie=name|--hash-source-ip]",
+		Short:         "Set load balancer settings for a service",
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			if len(args) > 0 {
+				options.serviceID = args[0]
+			}
+
+			if options.serviceID == "" {
+				return errors.New("service must be specified")
+			}
+
+			options.serviceName, err = common.ParseServiceID(options.serviceID)
+			if err != nil {
+				return err
+			}
+
+			if options.policy == "" && options.hashHeader == "" && options.hashCookie == "" && !options.hashSourceIP {
+				err = c.askQuestions(cli, options)
+				if err != nil {
+					return err
+				}
+			}
+
+			options.cookieTTLSet = cmd.Flags().Changed("cookie-ttl")
+			options.loadBalancer, err = options.getLoadBalancerSettings()
+			if err != nil {
+				return err
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.serviceID, "service", "", "Service name")
+	flags.StringVar(&options.policy, "policy", "", "Load balancing policy (round-robin, least-conn, random or passthrough)")
+	flags.StringVar(&options.hashHeader, "hash-header", "", "Use consistent hashing based on the value of this HTTP header")
+	flags.StringVar(&options.hashCookie, "hash-cookie", "", "Use consistent hashing based on the value of this HTTP cookie")
+	flags.StringVar(&options.cookiePath, "cookie-path", "", "Path of the HTTP cookie used for consistent hashing")
+	flags.DurationVar(&options.cookieTTL, "cookie-ttl", options.cookieTTL, "Lifetime of the HTTP cookie used for consistent hashing, the cookie is generated by the proxy if it is not present")
+	flags.BoolVar(&options.hashSourceIP, "hash-source-ip", options.hashSourceIP, "Use consistent hashing based on the source IP address")
+	flags.Uint64Var(&options.minimumRingSize, "minimum-ring-size", options.minimumRingSize, "Minimum number of virtual nodes to use for the hash ring")
+
+	return cmd
+}
+
+func (o *setOptions) getLoadBalancerSettings() (*v1alpha3.LoadBalancerSettings, error) {
+	hashSources := 0
+	for _, set := range []bool{o.hashHeader != "", o.hashCookie != "", o.hashSourceIP} {
+		if set {
+			hashSources++
+		}
+	}
+
+	if hashSources > 1 {
+		return nil, errors.New("only one of --hash-header, --hash-cookie and --hash-source-ip can be specified")
+	}
+
+	if o.hashCookie == "" && (o.cookiePath != "" || o.cookieTTLSet) {
+		return nil, errors.New("--cookie-path and --cookie-ttl can only be used together with --hash-cookie")
+	}
+
+	if hashSources == 0 {
+		if o.policy == "" {
+			return nil, errors.New("either --policy or one of --hash-header, --hash-cookie and --hash-source-ip must be specified")
+		}
+		if o.minimumRingSize > 0 {
+			return nil, errors.New("--minimum-ring-size can only be used together with consistent hashing")
+		}
+
+		policy, err := parseSimplePolicy(o.policy)
+		if err != nil {
+			return nil, err
+		}
+
+		return &v1alpha3.LoadBalancerSettings{
+			Simple: policy,
+		}, nil
+	}
+
+	if o.policy != "" {
+		return nil, errors.New("--policy cannot be used together with consistent hashing")
+	}
+
+	consistentHash := &v1alpha3.ConsistentHashLB{
+		HTTPHeaderName:  o.hashHeader,
+		UseSourceIP:     o.hashSourceIP,
+		MinimumRingSize: o.minimumRingSize,
+	}
+	if o.hashCookie != "" {
+		consistentHash.HTTPCookie = &v1alpha3.HTTPCookie{
+			Name: o.hashCookie,
+			Path: o.cookiePath,
+		}
+		// the TTL is only set if it is specified, since the proxy generates the cookie if it has a TTL, even if it is 0
+		if o.cookieTTLSet {
+			consistentHash.HTTPCookie.TTL = o.cookieTTL.String()
+		}
+	}
+
+	return &v1alpha3.LoadBalancerSettings{
+		ConsistentHash: consistentHash,
+	}, nil
+}
+
+func (c *setCommand) askQuestions(cli cli.CLI, options *setOptions) error {
+	var err error
+
+	if !cli.InteractiveTerminal() {
+		return nil
+	}
+
+	policy := ""
+	err = survey.AskOne(&survey.Select{
+		Message: "Load balancing policy",
+		Options: []string{"round-robin", "least-conn", "random", "passthrough", consistentHashOption},
+	}, &policy)
+	if err != nil {
+		return errors.Wrap(err, "error while asking question")
+	}
+
+	if policy != consistentHashOption {
+		options.policy = policy
+		return nil
+	}
+
+	hashOn := ""
+	err = survey.AskOne(&survey.Select{
+		Message: "Hash on",
+		Options: []string{hashOnHeader, hashOnCookie, hashOnSourceIP},
+	}, &hashOn)
+	if err != nil {
+		return errors.Wrap(err, "error while asking question")
+	}
+
+	switch hashOn {
+	case hashOnHeader:
+		err = survey.AskOne(&survey.Input{Message: "Name of the HTTP header"}, &options.hashHeader, survey.WithValidator(survey.Required))
+	case hashOnCookie:
+		err = survey.AskOne(&survey.Input{Message: "Name of the HTTP cookie"}, &options.hashCookie, survey.WithValidator(survey.Required))
+	case hashOnSourceIP:
+		options.hashSourceIP = true
+	}
+	if err != nil {
+		return errors.Wrap(err, "error while asking question")
+	}
+
+	return nil
+}
+
+func (c *setCommand) run(cli cli.CLI, options *setOptions) error {
+	var err error
+
+	service, err := common.GetServiceByName(cli, options.serviceName)
+	if err != nil {
+		if k8serrors.IsNotFound(errors.Cause(err)) {
+			return err
+		}
+		return errors.WrapIf(err, "could not get service")
+	}
+
+	client, err := cli.GetGraphQLClient()
+	if err != nil {
+		return errors.WrapIf(err, "could not get initialized graphql client")
+	}
+
+	req := graphql.ApplyGlobalTrafficPolicyRequest{
+		Name:         service.Name,
+		Namespace:    service.Namespace,
+		LoadBalancer: options.loadBalancer,
+	}
+
+	r, err := client.ApplyGlobalTrafficPolicy(cli.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	if !r {
+		return errors.New("unknown error: cannot apply load balancer settings")
+	}
+
+	data, err := getLoadBalancerSettingsByServiceName(cli, options.serviceName)
+	if err != nil {
+		if clierrors.IsNotFound(err) {
+			log.Infof("no load balancer settings set for %s", options.serviceName)
+			return nil
+		}
+		return err
+	}
+
+	if cli.InteractiveTerminal() {
+		log.Infof("load balancer settings successfully applied to %s", options.serviceName)
+	}
+
+	return Output(cli, data)
+}
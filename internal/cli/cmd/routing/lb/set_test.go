@@ -0,0 +1,108 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lb
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli/clitest"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql/graphqltest"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+)
+
+// checkLoadBalancer checks the load balancer settings of the movies service in the request of the mutation
+func checkLoadBalancer(want *v1alpha3.LoadBalancerSettings) func(t *testing.T, mutation graphqltest.Request, k8sClient k8sclient.Client) {
+	return func(t *testing.T, mutation graphqltest.Request, k8sClient k8sclient.Client) {
+		var req graphql.ApplyGlobalTrafficPolicyRequest
+		if err := mutation.DecodeVariable("input", &req); err != nil {
+			t.Fatal(err)
+		}
+		if req.Name != "movies" || req.Namespace != "backyards-demo" {
+			t.Errorf("unexpected service: %s/%s", req.Namespace, req.Name)
+		}
+		if !reflect.DeepEqual(req.LoadBalancer, want) {
+			t.Errorf("unexpected load balancer settings\ngot : %+v\nwant: %+v", req.LoadBalancer, want)
+		}
+	}
+}
+
+func TestSetCommand(t *testing.T) {
+	clitest.MutationTests{
+		Field:      "applyGlobalTrafficPolicy",
+		NewCommand: newSetCommand,
+		Objects:    []runtime.Object{clitest.NewDemoService()},
+		Cases: []clitest.MutationTestCase{
+			{
+				Name:  "sets a simple policy",
+				Args:  []string{"backyards-demo/movies", "--policy=least-conn"},
+				Check: checkLoadBalancer(&v1alpha3.LoadBalancerSettings{Simple: v1alpha3.SimpleLBLeastConn}),
+			},
+			{
+				Name: "leaves the cookie TTL empty if it is not specified",
+				Args: []string{"backyards-demo/movies", "--hash-cookie=session", "--cookie-path=/"},
+				Check: checkLoadBalancer(&v1alpha3.LoadBalancerSettings{
+					ConsistentHash: &v1alpha3.ConsistentHashLB{
+						HTTPCookie: &v1alpha3.HTTPCookie{Name: "session", Path: "/"},
+					},
+				}),
+			},
+			{
+				Name: "sets the cookie TTL if it is specified",
+				Args: []string{"backyards-demo/movies", "--hash-cookie=session", "--cookie-ttl=1h"},
+				Check: checkLoadBalancer(&v1alpha3.LoadBalancerSettings{
+					ConsistentHash: &v1alpha3.ConsistentHashLB{
+						HTTPCookie: &v1alpha3.HTTPCookie{Name: "session", TTL: "1h0m0s"},
+					},
+				}),
+			},
+			{
+				Name: "sets a zero cookie TTL if it is specified",
+				Args: []string{"backyards-demo/movies", "--hash-cookie=session", "--cookie-ttl=0"},
+				Check: checkLoadBalancer(&v1alpha3.LoadBalancerSettings{
+					ConsistentHash: &v1alpha3.ConsistentHashLB{
+						HTTPCookie: &v1alpha3.HTTPCookie{Name: "session", TTL: "0s"},
+					},
+				}),
+			},
+			{
+				Name: "sets a header hash with a minimum ring size",
+				Args: []string{"backyards-demo/movies", "--hash-header=x-user", "--minimum-ring-size=2048"},
+				Check: checkLoadBalancer(&v1alpha3.LoadBalancerSettings{
+					ConsistentHash: &v1alpha3.ConsistentHashLB{HTTPHeaderName: "x-user", MinimumRingSize: 2048},
+				}),
+			},
+			{
+				Name:    "requires a cookie for the cookie TTL",
+				Args:    []string{"backyards-demo/movies", "--hash-header=x-user", "--cookie-ttl=0"},
+				WantErr: true,
+			},
+			{
+				Name:    "rejects a policy together with consistent hashing",
+				Args:    []string{"backyards-demo/movies", "--policy=random", "--hash-source-ip"},
+				WantErr: true,
+			},
+			{
+				Name:    "fails for unknown services",
+				Args:    []string{"backyards-demo/books", "--policy=random"},
+				WantErr: true,
+			},
+		},
+	}.Run(t)
+}
@@ -0,0 +1,229 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/cb"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/ts"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+	"github.com/banzaicloud/backyards-cli/pkg/output"
+)
+
+type listCommand struct{}
+
+type listOptions struct {
+	namespace     string
+	allNamespaces bool
+}
+
+type HostRoutingRules struct {
+	Host           string                     `json:"host" yaml:"host"`
+	Routes         []ts.TrafficShiftingRule   `json:"routes,omitempty" yaml:"routes,omitempty"`
+	CircuitBreaker *cb.CircuitBreakerSettings `json:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
+}
+
+func newListOptions() *listOptions {
+	return &listOptions{}
+}
+
+func newListCommand(cli cli.CLI) *cobra.Command {
+	c := &listCommand{}
+	options := newListOptions()
+
+	cmd := &cobra.Command{
+		Use:     "list [namespace]",
+		Aliases: []string{"ls"},
+		Short:   "List routing rules of every service in a namespace or in the whole mesh",
+		Long: `List routing rules of every service in a namespace or in the whole mesh.
+
+The namespace to list is given as an argument, without it the namespace of the current
+kubeconfig context is used. The global -n/--namespace flag selects the namespace in which
+Backyards is installed, like for every other command, so it cannot be used as a filter here.`,
+		Example: `  # List the routing rules of the services in the backyards-demo namespace.
+  backyards routing list backyards-demo
+
+  # List the routing rules of every service in the mesh.
+  backyards routing list -A`,
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			if len(args) > 0 {
+				options.namespace = args[0]
+			}
+
+			if options.namespace != "" && options.allNamespaces {
+				return errors.New("namespace cannot be specified together with --all-namespaces")
+			}
+
+			if options.namespace == "" && !options.allNamespaces {
+				options.namespace, err = k8sclient.GetNamespaceWithContext(viper.GetString("kubeconfig"), viper.GetString("kubecontext"))
+				if err != nil {
+					return errors.WrapIf(err, "could not get namespace from kubeconfig")
+				}
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&options.allNamespaces, "all-namespaces", "A", options.allNamespaces, "List routing rules across all namespaces")
+
+	return cmd
+}
+
+func (c *listCommand) run(cli cli.CLI, options *listOptions) error {
+	var err error
+
+	cl, err := cli.GetK8sClient()
+	if err != nil {
+		return err
+	}
+
+	listOpts := make([]client.ListOptionFunc, 0)
+	if !options.allNamespaces {
+		listOpts = append(listOpts, client.InNamespace(options.namespace))
+	}
+
+	var vservices v1alpha3.VirtualServiceList
+	err = cl.List(context.Background(), &vservices, listOpts...)
+	if err != nil {
+		return errors.WrapIf(err, "could not list virtual services")
+	}
+
+	var drules v1alpha3.DestinationRuleList
+	err = cl.List(context.Background(), &drules, listOpts...)
+	if err != nil {
+		return errors.WrapIf(err, "could not list destination rules")
+	}
+
+	rules := make(map[string]*HostRoutingRules)
+	getRules := func(host, namespace string) *HostRoutingRules {
+		host = common.GetFQDN(host, namespace)
+		if _, ok := rules[host]; !ok {
+			rules[host] = &HostRoutingRules{
+				Host: host,
+			}
+		}
+		return rules[host]
+	}
+
+	for i := range vservices.Items {
+		vservice := &vservices.Items[i]
+		routes := ts.GetTrafficShiftingRules(vservice)
+		if len(routes) == 0 {
+			continue
+		}
+		for _, host := range vservice.Spec.Hosts {
+			r := getRules(host, vservice.Namespace)
+			r.Routes = append(r.Routes, routes...)
+		}
+	}
+
+	for _, drule := range drules.Items {
+		settings := cb.GetCircuitBreakerSettings(drule.Spec.TrafficPolicy)
+		if settings == nil {
+			continue
+		}
+		getRules(drule.Spec.Host, drule.Namespace).CircuitBreaker = settings
+	}
+
+	if len(rules) == 0 {
+		log.Info("no routing rules found")
+		return nil
+	}
+
+	hosts := make([]string, 0, len(rules))
+	for host := range rules {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	data := make([]HostRoutingRules, 0, len(hosts))
+	for _, host := range hosts {
+		data = append(data, *rules[host])
+	}
+
+	ctx := &output.Context{
+		Out:     cli.Out(),
+		Color:   cli.Color(),
+		Format:  cli.OutputFormat(),
+		Fields:  []string{"Host", "Weights", "ConnectionPool", "OutlierDetection"},
+		Headers: []string{"Host", "Weights", "Connection pool", "Outlier detection"},
+	}
+
+	err = output.Output(ctx, data)
+	if err != nil {
+		return errors.WrapIf(err, "could not produce output")
+	}
+
+	return nil
+}
+
+// Weights returns the weighted routes of the host along with their match conditions
+func (r HostRoutingRules) Weights() string {
+	if len(r.Routes) == 0 {
+		return "-"
+	}
+
+	routes := make([]string, 0, len(r.Routes))
+	for _, route := range r.Routes {
+		if len(route.Matches) == 0 {
+			routes = append(routes, route.Weights.String())
+			continue
+		}
+		routes = append(routes, fmt.Sprintf("%s: %s", route.Matches, route.Weights))
+	}
+
+	return strings.Join(routes, "; ")
+}
+
+// ConnectionPool returns the connection pool settings of the host in a condensed format
+func (r HostRoutingRules) ConnectionPool() string {
+	s := r.CircuitBreaker
+	if s == nil || !s.HasConnectionPool() {
+		return "-"
+	}
+
+	return fmt.Sprintf("connections=%d, timeout=%s, pending=%d, requests=%d, rpc=%d, retries=%d",
+		s.MaxConnections, s.ConnectTimeout, s.HTTP1MaxPendingRequests, s.HTTP2MaxRequests, s.MaxRequestsPerConnection, s.MaxRetries)
+}
+
+// OutlierDetection returns the outlier detection settings of the host in a condensed format
+func (r HostRoutingRules) OutlierDetection() string {
+	s := r.CircuitBreaker
+	if s == nil || !s.HasOutlierDetection() {
+		return "-"
+	}
+
+	return fmt.Sprintf("errors=%d, interval=%s, ejection=%s, percentage=%d",
+		s.ConsecutiveErrors, s.Interval, s.BaseEjectionTime, s.MaxEjectionPercent)
+}
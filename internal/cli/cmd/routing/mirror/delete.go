@@ -0,0 +1,150 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"emperror.dev/errors"
+	"github.com/AlecAivazis/survey/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	clierrors "github.com/banzaicloud/backyards-cli/internal/errors"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+)
+
+type deleteCommand struct{}
+
+type deleteOptions struct {
+	serviceID string
+	matches   []string
+
+	serviceName   types.NamespacedName
+	parsedMatches []v1alpha3.HTTPMatchRequest
+}
+
+func newDeleteOptions() *deleteOptions {
+	return &deleteOptions{}
+}
+
+func newDeleteCommand(cli cli.CLI) *cobra.Command {
+	c := &deleteCommand{}
+	options := newDeleteOptions()
+
+	cmd := &cobra.Command{
+		Use:           "delete [[--service=]namespace/servicename] [--match=field=value] ...",
+		Short:         "Delete traffic mirroring rules of a service",
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			if len(args) > 0 {
+				options.serviceID = args[0]
+			}
+
+			if options.serviceID == "" {
+				return errors.New("service must be specified")
+			}
+
+			options.serviceName, err = common.ParseServiceID(options.serviceID)
+			if err != nil {
+				return err
+			}
+
+			options.parsedMatches, err = common.ParseHTTPMatchRequests(options.matches)
+			if err != nil {
+				return err
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.serviceID, "service", "", "Service name")
+	flags.StringArrayVar(&options.matches, "match", []string{}, common.MatchFlagUsage)
+
+	return cmd
+}
+
+func (c *deleteCommand) run(cli cli.CLI, options *deleteOptions) error {
+	var err error
+
+	service, err := common.GetServiceByName(cli, options.serviceName)
+	if err != nil {
+		if k8serrors.IsNotFound(errors.Cause(err)) {
+			return err
+		}
+		return errors.WrapIf(err, "could not get service")
+	}
+
+	if cli.InteractiveTerminal() {
+		rules, err := getTrafficMirroringRulesByServiceName(cli, options.serviceName)
+		if err != nil {
+			if clierrors.IsNotFound(err) {
+				log.Infof("no traffic mirroring rules set for %s", options.serviceName)
+				return nil
+			}
+			return err
+		}
+
+		log.Info("current settings")
+
+		err = Output(cli, rules)
+		if err != nil {
+			return err
+		}
+
+		confirmed := false
+		err = survey.AskOne(&survey.Confirm{Message: "Do you want to DELETE the traffic mirroring rules?"}, &confirmed)
+		if err != nil {
+			return errors.WrapIf(err, "could not ask for confirmation")
+		}
+		if !confirmed {
+			return errors.New("deletion cancelled")
+		}
+	}
+
+	client, err := cli.GetGraphQLClient()
+	if err != nil {
+		return errors.WrapIf(err, "could not get initialized graphql client")
+	}
+
+	req := graphql.DisableHTTPRouteRequest{
+		Name:      service.Name,
+		Namespace: service.Namespace,
+		Match:     options.parsedMatches,
+		Rules: []string{
+			"Mirror",
+		},
+	}
+	r, err := client.DisableHTTPRoute(cli.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	if !r {
+		return errors.New("unknown error: cannot delete traffic mirroring")
+	}
+
+	log.Infof("traffic mirroring rules of %s successfully deleted", options.serviceName)
+
+	return nil
+}
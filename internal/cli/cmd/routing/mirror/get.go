@@ -0,0 +1,158 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"emperror.dev/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	clierrors "github.com/banzaicloud/backyards-cli/internal/errors"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+)
+
+const defaultMirrorPercentage = 100
+
+type getCommand struct{}
+
+type getOptions struct {
+	serviceID string
+
+	serviceName types.NamespacedName
+}
+
+type TrafficMirroringRule struct {
+	Matches    common.Matches `json:"matches,omitempty" yaml:"matches,omitempty"`
+	Host       string         `json:"host" yaml:"host"`
+	Subset     string         `json:"subset,omitempty" yaml:"subset,omitempty"`
+	Percentage int            `json:"percentage" yaml:"percentage"`
+}
+
+// mirroredHTTPRoute holds the mirroring related fields of an HTTP route,
+// mirrorPercent is missing from the typed VirtualService definition
+type mirroredHTTPRoute struct {
+	Match         []v1alpha3.HTTPMatchRequest `json:"match,omitempty"`
+	Mirror        *v1alpha3.Destination       `json:"mirror,omitempty"`
+	MirrorPercent *int                        `json:"mirrorPercent,omitempty"`
+}
+
+func newGetOptions() *getOptions {
+	return &getOptions{}
+}
+
+func newGetCommand(cli cli.CLI) *cobra.Command {
+	c := &getCommand{}
+	options := newGetOptions()
+
+	cmd := &cobra.Command{
+		Use:           "get [[--service=]namespace/servicename]",
+		Short:         "Get traffic mirroring rules for a service",
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			if len(args) > 0 {
+				options.serviceID = args[0]
+			}
+
+			if options.serviceID == "" {
+				return errors.New("service must be specified")
+			}
+
+			options.serviceName, err = common.ParseServiceID(options.serviceID)
+			if err != nil {
+				return err
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.serviceID, "service", "", "Service name")
+
+	return cmd
+}
+
+func getTrafficMirroringRulesByServiceName(cli cli.CLI, serviceName types.NamespacedName) ([]TrafficMirroringRule, error) {
+	var err error
+
+	_, err = common.GetServiceByName(cli, serviceName)
+	if err != nil {
+		if k8serrors.IsNotFound(errors.Cause(err)) {
+			return nil, err
+		}
+		return nil, errors.WrapIf(err, "could not get service")
+	}
+
+	var routes []mirroredHTTPRoute
+	err = common.GetVirtualserviceHTTPRoutes(cli, serviceName, &routes)
+	if err != nil {
+		if k8serrors.IsNotFound(errors.Cause(err)) {
+			return nil, clierrors.NotFoundError{}
+		}
+		return nil, err
+	}
+
+	rules := make([]TrafficMirroringRule, 0)
+	for _, route := range routes {
+		if route.Mirror == nil {
+			continue
+		}
+
+		percentage := defaultMirrorPercentage
+		if route.MirrorPercent != nil {
+			percentage = *route.MirrorPercent
+		}
+
+		rules = append(rules, TrafficMirroringRule{
+			Matches:    common.FormatHTTPMatchRequests(route.Match),
+			Host:       route.Mirror.Host,
+			Subset:     route.Mirror.Subset,
+			Percentage: percentage,
+		})
+	}
+
+	if len(rules) == 0 {
+		return nil, clierrors.NotFoundError{}
+	}
+
+	return rules, nil
+}
+
+func (c *getCommand) run(cli cli.CLI, options *getOptions) error {
+	var err error
+
+	rules, err := getTrafficMirroringRulesByServiceName(cli, options.serviceName)
+	if err != nil {
+		if clierrors.IsNotFound(err) {
+			log.Infof("no traffic mirroring rules set for %s", options.serviceName)
+			return nil
+		}
+		return err
+	}
+
+	err = Output(cli, rules)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"emperror.dev/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/ts"
+	clierrors "github.com/banzaicloud/backyards-cli/internal/errors"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+)
+
+type setCommand struct{}
+
+type setOptions struct {
+	serviceID     string
+	subset        string
+	percentage    int
+	matches       []string
+	createSubsets bool
+
+	serviceName   types.NamespacedName
+	parsedMatches []v1alpha3.HTTPMatchRequest
+}
+
+func newSetOptions() *setOptions {
+	return &setOptions{
+		percentage: defaultMirrorPercentage,
+	}
+}
+
+func newSetCommand(cli cli.CLI) *cobra.Command {
+	c := &setCommand{}
+	options := newSetOptions()
+
+	cmd := &cobra.Command{
+		Use:           "set [[--service=]namespace/servicename] [--subset=]subset [--percentage=percentage]",
+		Short:         "Set traffic mirroring rules for a service",
+		Args:          cobra.MaximumNArgs(2),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			if len(args) > 0 {
+				options.serviceID = args[0]
+			}
+
+			if len(args) > 1 {
+				options.subset = args[1]
+			}
+
+			if options.serviceID == "" {
+				return errors.New("service must be specified")
+			}
+
+			if options.subset == "" {
+				return errors.New("subset must be specified")
+			}
+
+			if options.percentage < 1 || options.percentage > 100 {
+				return errors.New("percentage must be between 1 and 100")
+			}
+
+			options.serviceName, err = common.ParseServiceID(options.serviceID)
+			if err != nil {
+				return err
+			}
+
+			options.parsedMatches, err = common.ParseHTTPMatchRequests(options.matches)
+			if err != nil {
+				return err
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.serviceID, "service", "", "Service name")
+	flags.StringVar(&options.subset, "subset", "", "Subset to mirror the traffic to")
+	flags.IntVar(&options.percentage, "percentage", options.percentage, "Percentage of the traffic to mirror")
+	flags.StringArrayVar(&options.matches, "match", []string{}, common.MatchFlagUsage)
+	flags.BoolVar(&options.createSubsets, "create-subsets", options.createSubsets, "Create the missing subset from the version labels of the pods without asking")
+
+	return cmd
+}
+
+func (c *setCommand) run(cli cli.CLI, options *setOptions) error {
+	var err error
+
+	service, err := common.GetServiceByName(cli, options.serviceName)
+	if err != nil {
+		if k8serrors.IsNotFound(errors.Cause(err)) {
+			return err
+		}
+		return errors.WrapIf(err, "could not get service")
+	}
+
+	err = ts.ValidateSubsets(cli, service, []string{options.subset}, options.createSubsets)
+	if err != nil {
+		return err
+	}
+
+	client, err := cli.GetGraphQLClient()
+	if err != nil {
+		return errors.WrapIf(err, "could not get initialized graphql client")
+	}
+
+	req := graphql.ApplyHTTPRouteRequest{
+		Name:      service.Name,
+		Namespace: service.Namespace,
+		Match:     options.parsedMatches,
+		Mirror: &graphql.Destination{
+			Host:   service.Name,
+			Subset: options.subset,
+		},
+		MirrorPercent: &options.percentage,
+	}
+
+	r, err := client.ApplyHTTPRoute(cli.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	if !r {
+		return errors.New("unknown error: cannot set traffic mirroring")
+	}
+
+	err = c.output(cli, options)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *setCommand) output(cli cli.CLI, options *setOptions) error {
+	rules, err := getTrafficMirroringRulesByServiceName(cli, options.serviceName)
+	if err != nil {
+		if clierrors.IsNotFound(err) {
+			log.Infof("no traffic mirroring rules set for %s", options.serviceName)
+			return nil
+		}
+		return err
+	}
+
+	if cli.InteractiveTerminal() {
+		log.Infof("traffic mirroring rules successfully applied to %s", options.serviceName)
+	}
+
+	err = Output(cli, rules)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli/clitest"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql/graphqltest"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+)
+
+func TestSetCommand(t *testing.T) {
+	percentage := func(p int) *int { return &p }
+
+	clitest.MutationTests{
+		Field:      "applyHTTPRoute",
+		NewCommand: newSetCommand,
+		Objects:    append([]runtime.Object{clitest.NewDemoService(), clitest.NewDemoDestinationRule("v1", "v2")}, clitest.NewDemoPods("v1", "v2", "v3")...),
+		Cases: []clitest.MutationTestCase{
+			{
+				Name: "mirrors traffic to a defined subset",
+				Args: []string{"backyards-demo/movies", "v2", "--percentage=30"},
+				WantInput: &graphql.ApplyHTTPRouteRequest{
+					Name:          "movies",
+					Namespace:     "backyards-demo",
+					Mirror:        &graphql.Destination{Host: "movies", Subset: "v2"},
+					MirrorPercent: percentage(30),
+				},
+			},
+			{
+				Name:    "rejects unknown subsets",
+				Args:    []string{"backyards-demo/movies", "v4"},
+				WantErr: true,
+			},
+			{
+				Name:    "rejects undefined subsets without --create-subsets",
+				Args:    []string{"backyards-demo/movies", "v3"},
+				WantErr: true,
+			},
+			{
+				Name: "creates an undefined subset from pod labels",
+				Args: []string{"backyards-demo/movies", "v3", "--create-subsets"},
+				WantInput: &graphql.ApplyHTTPRouteRequest{
+					Name:          "movies",
+					Namespace:     "backyards-demo",
+					Mirror:        &graphql.Destination{Host: "movies", Subset: "v3"},
+					MirrorPercent: percentage(defaultMirrorPercentage),
+				},
+				Check: func(t *testing.T, _ graphqltest.Request, k8sClient k8sclient.Client) {
+					var drule v1alpha3.DestinationRule
+					err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: "backyards-demo", Name: "movies"}, &drule)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if len(drule.Spec.Subsets) != 3 {
+						t.Errorf("expected 3 subsets, got %+v", drule.Spec.Subsets)
+					}
+				},
+			},
+			{
+				Name:    "fails for unknown services",
+				Args:    []string{"backyards-demo/books", "v1"},
+				WantErr: true,
+			},
+		},
+	}.Run(t)
+}
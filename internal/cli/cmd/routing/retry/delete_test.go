@@ -0,0 +1,86 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis/istio/common/v1alpha1"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli/clitest"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql/graphqltest"
+)
+
+func TestDeleteCommand(t *testing.T) {
+	clitest.MutationTests{
+		Field:      "disableHTTPRoute",
+		NewCommand: newDeleteCommand,
+		Objects:    []runtime.Object{clitest.NewDemoService()},
+		Cases: []clitest.MutationTestCase{
+			{
+				Name: "deletes the timeout and the retry rules by default",
+				Args: []string{"backyards-demo/movies"},
+				WantInput: &graphql.DisableHTTPRouteRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Rules:     []string{"Timeout", "Retries"},
+				},
+			},
+			{
+				Name: "deletes the timeout only",
+				Args: []string{"backyards-demo/movies", "--timeout"},
+				WantInput: &graphql.DisableHTTPRouteRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Rules:     []string{"Timeout"},
+				},
+			},
+			{
+				Name: "deletes the retries of matching requests only",
+				Args: []string{"backyards-demo/movies", "--retries", "--match=method=GET"},
+				WantInput: &graphql.DisableHTTPRouteRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Match:     []v1alpha3.HTTPMatchRequest{{Method: &v1alpha1.StringMatch{Exact: "GET"}}},
+					Rules:     []string{"Retries"},
+				},
+			},
+			{
+				Name:    "fails if the backend does not confirm the deletion",
+				Args:    []string{"backyards-demo/movies"},
+				Respond: func(s *graphqltest.Server) { s.Respond("disableHTTPRoute", false) },
+				WantErr: true,
+				WantInput: &graphql.DisableHTTPRouteRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Rules:     []string{"Timeout", "Retries"},
+				},
+			},
+			{
+				Name:    "fails for unknown services",
+				Args:    []string{"backyards-demo/books"},
+				WantErr: true,
+			},
+			{
+				Name:    "requires a service",
+				Args:    []string{},
+				WantErr: true,
+			},
+		},
+	}.Run(t)
+}
@@ -0,0 +1,212 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/AlecAivazis/survey/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	clierrors "github.com/banzaicloud/backyards-cli/internal/errors"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+	"github.com/banzaicloud/backyards-cli/pkg/questionnaire"
+)
+
+type setCommand struct{}
+
+type RetrySettings struct {
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty" survey.question:"Timeout for HTTP requests" survey.validate:"durationstring"`
+
+	// Retries
+	Attempts      int32  `json:"attempts,omitempty" yaml:"attempts,omitempty" survey.question:"Number of retries for a given request" survey.validate:"int"`
+	PerTryTimeout string `json:"perTryTimeout,omitempty" yaml:"perTryTimeout,omitempty" survey.question:"Timeout per retry attempt" survey.validate:"durationstring"`
+	RetryOn       string `json:"retryOn,omitempty" yaml:"retryOn,omitempty" survey.question:"Conditions under which retry takes place"`
+}
+
+type setOptions struct {
+	serviceID string
+	matches   []string
+
+	RetrySettings
+	timeout       time.Duration
+	perTryTimeout time.Duration
+
+	serviceName   types.NamespacedName
+	parsedMatches []v1alpha3.HTTPMatchRequest
+}
+
+func newSetOptions() *setOptions {
+	return &setOptions{
+		RetrySettings: RetrySettings{
+			Timeout:       "15s",
+			Attempts:      3,
+			PerTryTimeout: "5s",
+			RetryOn:       "5xx,connect-failure,refused-stream",
+		},
+		timeout:       15 * time.Second,
+		perTryTimeout: 5 * time.Second,
+	}
+}
+
+func newSetCommand(cli cli.CLI) *cobra.Command {
+	c := &setCommand{}
+	options := newSetOptions()
+
+	cmd := &cobra.Command{
+		Use:           "set [[--service=]namespace/servicename]",
+		Short:         "Set retry and timeout rules for a service",
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			if len(args) > 0 {
+				options.serviceID = args[0]
+			}
+
+			if options.serviceID == "" {
+				return errors.New("service must be specified")
+			}
+
+			options.Timeout = options.timeout.String()
+			options.PerTryTimeout = options.perTryTimeout.String()
+
+			options.serviceName, err = common.ParseServiceID(options.serviceID)
+			if err != nil {
+				return err
+			}
+
+			options.parsedMatches, err = common.ParseHTTPMatchRequests(options.matches)
+			if err != nil {
+				return err
+			}
+
+			err = c.askQuestions(cli, options)
+			if err != nil {
+				return err
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.serviceID, "service", "", "Service name")
+	flags.StringArrayVar(&options.matches, "match", []string{}, common.MatchFlagUsage)
+
+	flags.DurationVar(&options.timeout, "timeout", options.timeout, "Timeout for HTTP requests")
+
+	// Retries
+	flags.Int32Var(&options.Attempts, "attempts", options.Attempts, "Number of retries for a given request")
+	flags.DurationVar(&options.perTryTimeout, "per-try-timeout", options.perTryTimeout, "Timeout per retry attempt for a given request")
+	flags.StringVar(&options.RetryOn, "retry-on", options.RetryOn, "Comma separated list of conditions under which retry takes place (e.g. 5xx, gateway-error, connect-failure, refused-stream, retriable-4xx)")
+
+	return cmd
+}
+
+func (c *setCommand) askQuestions(cli cli.CLI, options *setOptions) error {
+	var err error
+
+	if !cli.InteractiveTerminal() {
+		return nil
+	}
+
+	qs, err := questionnaire.GetQuestionsFromStruct(options.RetrySettings)
+	if err != nil {
+		return err
+	}
+
+	err = survey.Ask(qs, &options.RetrySettings)
+	if err != nil {
+		return errors.Wrap(err, "error while asking question")
+	}
+
+	return nil
+}
+
+func (c *setCommand) run(cli cli.CLI, options *setOptions) error {
+	var err error
+
+	service, err := common.GetServiceByName(cli, options.serviceName)
+	if err != nil {
+		if k8serrors.IsNotFound(errors.Cause(err)) {
+			return err
+		}
+		return errors.WrapIf(err, "could not get service")
+	}
+
+	client, err := cli.GetGraphQLClient()
+	if err != nil {
+		return errors.WrapIf(err, "could not get initialized graphql client")
+	}
+
+	req := graphql.ApplyHTTPRouteRequest{
+		Name:      service.Name,
+		Namespace: service.Namespace,
+		Match:     options.parsedMatches,
+		Timeout:   options.Timeout,
+		Retries: &graphql.HTTPRetry{
+			Attempts:      int(options.Attempts),
+			PerTryTimeout: options.PerTryTimeout,
+			RetryOn:       options.RetryOn,
+		},
+	}
+
+	r, err := client.ApplyHTTPRoute(cli.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	if !r {
+		return errors.New("unknown error: cannot apply retry settings")
+	}
+
+	err = c.output(cli, options)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *setCommand) output(cli cli.CLI, options *setOptions) error {
+	rules, err := getRetryRulesByServiceName(cli, options.serviceName)
+	if err != nil {
+		if clierrors.IsNotFound(err) {
+			log.Infof("no retry rules set for %s", options.serviceName)
+			return nil
+		}
+		return err
+	}
+
+	if cli.InteractiveTerminal() {
+		log.Infof("retry rules successfully applied to %s", options.serviceName)
+	}
+
+	err = Output(cli, rules)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis/istio/common/v1alpha1"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli/clitest"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql/graphqltest"
+)
+
+func TestSetCommand(t *testing.T) {
+	defaultRetries := &graphql.HTTPRetry{
+		Attempts:      3,
+		PerTryTimeout: "5s",
+		RetryOn:       "5xx,connect-failure,refused-stream",
+	}
+
+	clitest.MutationTests{
+		Field:      "applyHTTPRoute",
+		NewCommand: newSetCommand,
+		Objects:    []runtime.Object{clitest.NewDemoService()},
+		Cases: []clitest.MutationTestCase{
+			{
+				Name: "uses the defaults",
+				Args: []string{"backyards-demo/movies"},
+				WantInput: &graphql.ApplyHTTPRouteRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Timeout:   "15s",
+					Retries:   defaultRetries,
+				},
+			},
+			{
+				Name: "sets the given retries for matching requests",
+				Args: []string{"backyards-demo/movies", "--timeout=1m", "--attempts=5", "--per-try-timeout=2s",
+					"--retry-on=gateway-error", "--match=header.x-user=exact:admin"},
+				WantInput: &graphql.ApplyHTTPRouteRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Match: []v1alpha3.HTTPMatchRequest{{
+						Headers: map[string]v1alpha1.StringMatch{"x-user": {Exact: "admin"}},
+					}},
+					Timeout: "1m0s",
+					Retries: &graphql.HTTPRetry{
+						Attempts:      5,
+						PerTryTimeout: "2s",
+						RetryOn:       "gateway-error",
+					},
+				},
+			},
+			{
+				Name:    "rejects invalid matches",
+				Args:    []string{"backyards-demo/movies", "--match=body=foo"},
+				WantErr: true,
+			},
+			{
+				Name:    "fails for unknown services",
+				Args:    []string{"backyards-demo/books"},
+				WantErr: true,
+			},
+			{
+				Name: "returns backend errors",
+				Args: []string{"backyards-demo/movies"},
+				Respond: func(s *graphqltest.Server) {
+					s.RespondError("applyHTTPRoute", "BAD_USER_INPUT", "invalid retry settings")
+				},
+				WantErr: true,
+				WantInput: &graphql.ApplyHTTPRouteRequest{
+					Name:      "movies",
+					Namespace: "backyards-demo",
+					Timeout:   "15s",
+					Retries:   defaultRetries,
+				},
+			},
+		},
+	}.Run(t)
+}
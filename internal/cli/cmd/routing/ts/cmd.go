@@ -31,6 +31,7 @@ func NewRootCmd(cli cli.CLI) *cobra.Command {
 		newGetCommand(cli),
 		newSetCommand(cli),
 		newDeleteCommand(cli),
+		newRolloutCommand(cli),
 	)
 
 	return cmd
@@ -22,6 +22,8 @@ import (
 	"strings"
 
 	"emperror.dev/errors"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
 )
 
 const dns1123LabelFmt string = "[a-z0-9]([-a-z0-9]*[a-z0-9])?"
@@ -30,6 +32,11 @@ var dns1123LabelRegexp = regexp.MustCompile("^" + dns1123LabelFmt + "$")
 
 type parsedSubsets map[string]int
 
+type TrafficShiftingRule struct {
+	Matches common.Matches `json:"matches,omitempty" yaml:"matches,omitempty"`
+	Weights parsedSubsets  `json:"weights" yaml:"weights"`
+}
+
 func (p parsedSubsets) String() string {
 	parts := make([]string, 0)
 	for subset, weight := range p {
@@ -60,7 +67,7 @@ func parseSubsets(subsets []string) (parsedSubsets, error) {
 
 	for _, subset := range subsets {
 		parts := strings.Split(subset, "=")
-		if len(parts) != 2 || !dns1123LabelRegexp.MatchString(parts[1]) {
+		if len(parts) != 2 || !dns1123LabelRegexp.MatchString(parts[0]) {
 			return nil, errors.Errorf("invalid subset: '%s': format must be <subset>=<weight>", subset)
 		}
 
@@ -0,0 +1,46 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ts
+
+import (
+	"testing"
+)
+
+func TestParseSubsets(t *testing.T) {
+	tests := map[string]struct {
+		subsets []string
+		err     bool
+	}{
+		"valid":          {subsets: []string{"v1=50", "v2=50"}},
+		"invalid name":   {subsets: []string{"V_1=50", "v2=50"}, err: true},
+		"invalid weight": {subsets: []string{"v1=fifty", "v2=50"}, err: true},
+		"invalid sum":    {subsets: []string{"v1=50", "v2=40"}, err: true},
+		"missing weight": {subsets: []string{"v1"}, err: true},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+
+		t.Run(name, func(t *testing.T) {
+			_, err := parseSubsets(test.subsets)
+			if test.err && err == nil {
+				t.Fatalf("expected error for %v", test.subsets)
+			}
+			if !test.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
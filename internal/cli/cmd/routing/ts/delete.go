@@ -20,6 +20,7 @@ import (
 	"github.com/spf13/cobra"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
 
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
 	"github.com/banzaicloud/backyards-cli/pkg/cli"
@@ -30,8 +31,10 @@ type deleteCommand struct{}
 
 type deleteOptions struct {
 	serviceID string
+	matches   []string
 
-	serviceName types.NamespacedName
+	serviceName   types.NamespacedName
+	parsedMatches []v1alpha3.HTTPMatchRequest
 }
 
 func newDeleteOptions() *deleteOptions {
@@ -43,7 +46,7 @@ func newDeleteCommand(cli cli.CLI) *cobra.Command {
 	options := newDeleteOptions()
 
 	cmd := &cobra.Command{
-		Use:           "delete [[--service=]namespace/servicename]",
+		Use:           "delete [[--service=]namespace/servicename] [--match=field=value] ...",
 		Short:         "Delete traffic shifting rules of a service",
 		Args:          cobra.MaximumNArgs(1),
 		SilenceErrors: true,
@@ -63,12 +66,18 @@ func newDeleteCommand(cli cli.CLI) *cobra.Command {
 				return err
 			}
 
+			options.parsedMatches, err = common.ParseHTTPMatchRequests(options.matches)
+			if err != nil {
+				return err
+			}
+
 			return c.run(cli, options)
 		},
 	}
 
 	flags := cmd.Flags()
 	flags.StringVar(&options.serviceID, "service", "", "Service name")
+	flags.StringArrayVar(&options.matches, "match", []string{}, common.MatchFlagUsage)
 
 	return cmd
 }
@@ -84,7 +93,7 @@ func (c *deleteCommand) run(cli cli.CLI, options *deleteOptions) error {
 		return errors.WrapIf(err, "could not get service")
 	}
 
-	client, err := common.GetGraphQLClient(cli)
+	client, err := cli.GetGraphQLClient()
 	if err != nil {
 		return errors.WrapIf(err, "could not get initialized graphql client")
 	}
@@ -92,11 +101,12 @@ func (c *deleteCommand) run(cli cli.CLI, options *deleteOptions) error {
 	req := graphql.DisableHTTPRouteRequest{
 		Name:      service.Name,
 		Namespace: service.Namespace,
+		Match:     options.parsedMatches,
 		Rules: []string{
 			"Route",
 		},
 	}
-	r, err := client.DisableHTTPRoute(req)
+	r, err := client.DisableHTTPRoute(cli.Context(), req)
 	if err != nil {
 		return err
 	}
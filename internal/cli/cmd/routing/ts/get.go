@@ -20,6 +20,7 @@ import (
 	"github.com/spf13/cobra"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
 
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
 	"github.com/banzaicloud/backyards-cli/pkg/cli"
@@ -92,18 +93,54 @@ func (c *getCommand) run(cli cli.CLI, options *getOptions) error {
 		return errors.WrapIf(err, "could not get service")
 	}
 
-	subsets := make(parsedSubsets)
+	rules := GetTrafficShiftingRules(vservice)
+	if len(rules) == 0 {
+		log.Infof("no traffic shifting rules set for %s", options.serviceName)
+		return nil
+	}
+
+	return Output(cli, rules)
+}
+
+// GetTrafficShiftingRules returns the weighted routes of a virtual service. Routes to a single destination
+// without a subset are plain routes, not traffic shifting rules, so they are left out.
+func GetTrafficShiftingRules(vservice *v1alpha3.VirtualService) []TrafficShiftingRule {
+	rules := make([]TrafficShiftingRule, 0)
 	for _, route := range vservice.Spec.HTTP {
-		if len(route.Match) > 0 {
+		if !isTrafficShiftingRoute(route) {
 			continue
 		}
 
+		subsets := make(parsedSubsets)
 		for _, r := range route.Route {
-			subsets[r.Destination.Subset] = r.Weight
+			weight := r.Weight
+			// the weight of a single destination is 100 if it is not set
+			if len(route.Route) == 1 && weight == 0 {
+				weight = 100
+			}
+			subsets[r.Destination.Subset] = weight
 		}
+
+		rules = append(rules, TrafficShiftingRule{
+			Matches: common.FormatHTTPMatchRequests(route.Match),
+			Weights: subsets,
+		})
 	}
 
-	log.Infof("traffic shifting for %s is currently set to %s", options.serviceName, subsets)
+	return rules
+}
+
+// isTrafficShiftingRoute returns whether the route splits the traffic between subsets
+func isTrafficShiftingRoute(route v1alpha3.HTTPRoute) bool {
+	if len(route.Route) == 0 {
+		return false
+	}
+
+	for _, r := range route.Route {
+		if r.Destination.Subset == "" {
+			return false
+		}
+	}
 
-	return nil
+	return true
 }
@@ -0,0 +1,382 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+)
+
+const (
+	versionLabel      = "version"
+	minimumRateWindow = time.Minute
+)
+
+type rolloutCommand struct{}
+
+type rolloutOptions struct {
+	serviceID    string
+	from         string
+	to           string
+	steps        []int
+	interval     time.Duration
+	maxErrorRate float64
+	maxLatency   time.Duration
+	allowNoData  bool
+	matches      []string
+
+	serviceName   types.NamespacedName
+	parsedMatches []v1alpha3.HTTPMatchRequest
+}
+
+func newRolloutOptions() *rolloutOptions {
+	return &rolloutOptions{
+		steps:        []int{10, 25, 50, 100},
+		interval:     2 * time.Minute,
+		maxErrorRate: 1,
+		maxLatency:   time.Second,
+	}
+}
+
+func newRolloutCommand(cli cli.CLI) *cobra.Command {
+	c := &rolloutCommand{}
+	options := newRolloutOptions()
+
+	cmd := &cobra.Command{
+		Use:   "rollout [[--service=]namespace/servicename] --from=subset --to=subset",
+		Short: "Gradually shift traffic of a service from one subset to another",
+		Long: `Gradually shift traffic of a service from one subset to another.
+
+The traffic is shifted to the new subset in steps. Between the steps the error rate and the
+95th percentile latency of the requests sent to the new subset are checked in Prometheus, and the
+original traffic shifting rules are restored if any of them exceeds its threshold, there is no data
+to check them (unless --allow-no-data is set), or the rollout is interrupted.`,
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			if len(args) > 0 {
+				options.serviceID = args[0]
+			}
+
+			if options.serviceID == "" {
+				return errors.New("service must be specified")
+			}
+
+			options.serviceName, err = common.ParseServiceID(options.serviceID)
+			if err != nil {
+				return err
+			}
+
+			err = options.validate()
+			if err != nil {
+				return err
+			}
+
+			options.parsedMatches, err = common.ParseHTTPMatchRequests(options.matches)
+			if err != nil {
+				return err
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.serviceID, "service", "", "Service name")
+	flags.StringVar(&options.from, "from", "", "Subset which currently receives the traffic")
+	flags.StringVar(&options.to, "to", "", "Subset to shift the traffic to")
+	flags.IntSliceVar(&options.steps, "steps", options.steps, "Increasing percentages of traffic sent to the new subset in each step")
+	flags.DurationVar(&options.interval, "interval", options.interval, "Time to wait between steps")
+	flags.Float64Var(&options.maxErrorRate, "max-error-rate", options.maxErrorRate, "Maximum percentage of failed (5xx) requests to the new subset")
+	flags.DurationVar(&options.maxLatency, "max-latency", options.maxLatency, "Maximum 95th percentile latency of the requests to the new subset")
+	flags.BoolVar(&options.allowNoData, "allow-no-data", options.allowNoData, "Continue the rollout if there are no metrics of the new subset to check")
+	flags.StringArrayVar(&options.matches, "match", []string{}, common.MatchFlagUsage)
+
+	return cmd
+}
+
+func (o *rolloutOptions) validate() error {
+	for _, subset := range []string{o.from, o.to} {
+		if subset == "" {
+			return errors.New("both --from and --to subsets must be specified")
+		}
+		if !dns1123LabelRegexp.MatchString(subset) {
+			return errors.Errorf("invalid subset: '%s'", subset)
+		}
+	}
+
+	if o.from == o.to {
+		return errors.New("--from and --to subsets must be different")
+	}
+
+	if len(o.steps) == 0 {
+		return errors.New("at least 1 step must be specified")
+	}
+
+	prev := 0
+	for _, step := range o.steps {
+		if step <= prev || step > 100 {
+			return errors.Errorf("invalid steps: %v: steps must be increasing percentages between 1 and 100", o.steps)
+		}
+		prev = step
+	}
+
+	if o.interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+
+	if o.maxErrorRate < 0 || o.maxErrorRate > 100 {
+		return errors.New("maximum error rate must be between 0 and 100")
+	}
+
+	return nil
+}
+
+func (c *rolloutCommand) run(cli cli.CLI, options *rolloutOptions) error {
+	var err error
+
+	service, err := common.GetServiceByName(cli, options.serviceName)
+	if err != nil {
+		if k8serrors.IsNotFound(errors.Cause(err)) {
+			return err
+		}
+		return errors.WrapIf(err, "could not get service")
+	}
+
+	err = ValidateSubsets(cli, service, []string{options.from, options.to}, false)
+	if err != nil {
+		return err
+	}
+
+	version, err := getSubsetVersion(cli, options.serviceName, options.to)
+	if err != nil {
+		return err
+	}
+
+	original, err := getTrafficShiftingRule(cli, options.serviceName, options.parsedMatches)
+	if err != nil {
+		return err
+	}
+
+	client, err := cli.GetGraphQLClient()
+	if err != nil {
+		return errors.WrapIf(err, "could not get initialized graphql client")
+	}
+
+	promAPI, err := common.GetPrometheusAPI(cli)
+	if err != nil {
+		return errors.WrapIf(err, "could not get initialized prometheus client")
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	for i, step := range options.steps {
+		weights := parsedSubsets{
+			options.to: step,
+		}
+		if step < 100 {
+			weights[options.from] = 100 - step
+		}
+
+		err = applyWeights(cli.Context(), client, options.serviceName, options.parsedMatches, weights)
+		if err != nil {
+			return c.rollback(client, options, original, err)
+		}
+		log.Infof("step %d/%d: traffic shifting for %s set to %s", i+1, len(options.steps), options.serviceName, weights)
+
+		if i == len(options.steps)-1 {
+			break
+		}
+
+		select {
+		case <-signals:
+			return c.rollback(client, options, original, errors.New("rollout interrupted"))
+		case <-time.After(options.interval):
+		}
+
+		err = c.checkMetrics(cli.Context(), promAPI, options, version)
+		if err != nil {
+			return c.rollback(client, options, original, err)
+		}
+	}
+
+	log.Infof("traffic of %s successfully shifted to %s", options.serviceName, options.to)
+
+	return nil
+}
+
+// checkMetrics checks the metrics reported by the clients of the service, so requests which did not even reach the new subset are counted as well
+func (c *rolloutCommand) checkMetrics(ctx context.Context, api promv1.API, options *rolloutOptions, version string) error {
+	window := options.interval
+	if window < minimumRateWindow {
+		window = minimumRateWindow
+	}
+
+	filter := strings.Join([]string{
+		`reporter="source"`,
+		fmt.Sprintf("destination_service_namespace=%q", options.serviceName.Namespace),
+		fmt.Sprintf("destination_service_name=%q", options.serviceName.Name),
+		fmt.Sprintf("destination_version=%q", version),
+	}, ",")
+	interval := model.Duration(window).String()
+
+	errorRate, ok, err := common.QueryPrometheusScalar(ctx, api, fmt.Sprintf(
+		`sum(rate(istio_requests_total{%[1]s,response_code=~"5.."}[%[2]s])) / sum(rate(istio_requests_total{%[1]s}[%[2]s])) * 100`,
+		filter, interval))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if !options.allowNoData {
+			return errors.Errorf("no requests to subset %s in the last %s, metrics cannot be checked", options.to, interval)
+		}
+		log.Warnf("no requests to subset %s in the last %s, skipping metric checks", options.to, interval)
+		return nil
+	}
+
+	latency, ok, err := common.QueryPrometheusScalar(ctx, api, fmt.Sprintf(
+		`histogram_quantile(0.95, sum(rate(istio_backyards_request_duration_seconds_bucket{%s}[%s])) by (le))`,
+		filter, interval))
+	if err != nil {
+		return err
+	}
+	if !ok && !options.allowNoData {
+		return errors.Errorf("no latency metrics of subset %s in the last %s, metrics cannot be checked", options.to, interval)
+	}
+	p95 := time.Duration(latency * float64(time.Second))
+
+	log.Infof("subset %s: error rate %.2f%%, p95 latency %s", options.to, errorRate, p95.Round(time.Millisecond))
+
+	if errorRate > options.maxErrorRate {
+		return errors.Errorf("error rate of subset %s is %.2f%%, which exceeds %.2f%%", options.to, errorRate, options.maxErrorRate)
+	}
+
+	if ok && p95 > options.maxLatency {
+		return errors.Errorf("p95 latency of subset %s is %s, which exceeds %s", options.to, p95.Round(time.Millisecond), options.maxLatency)
+	}
+
+	return nil
+}
+
+// rollback restores the original traffic shifting rule, or removes the rule if there was none
+func (c *rolloutCommand) rollback(client graphql.Client, options *rolloutOptions, original *TrafficShiftingRule, cause error) error {
+	log.Errorf("rollout failed: %s", cause)
+
+	// the context of the command is already cancelled if the rollout was interrupted
+	ctx := context.Background()
+
+	if original != nil {
+		err := applyWeights(ctx, client, options.serviceName, options.parsedMatches, original.Weights)
+		if err != nil {
+			return errors.Combine(cause, errors.WrapIf(err, "could not roll back traffic shifting"))
+		}
+		log.Infof("traffic shifting for %s rolled back to %s", options.serviceName, original.Weights)
+
+		return cause
+	}
+
+	r, err := client.DisableHTTPRoute(ctx, graphql.DisableHTTPRouteRequest{
+		Name:      options.serviceName.Name,
+		Namespace: options.serviceName.Namespace,
+		Match:     options.parsedMatches,
+		Rules:     []string{"Route"},
+	})
+	if err == nil && !r {
+		err = errors.New("unknown error: cannot delete traffic shifting")
+	}
+	if err != nil {
+		return errors.Combine(cause, errors.WrapIf(err, "could not roll back traffic shifting"))
+	}
+	log.Infof("traffic shifting rules of %s removed", options.serviceName)
+
+	return cause
+}
+
+func applyWeights(ctx context.Context, client graphql.Client, serviceName types.NamespacedName, match []v1alpha3.HTTPMatchRequest, weights parsedSubsets) error {
+	r, err := client.ApplyHTTPRoute(ctx, NewApplyHTTPRouteRequest(serviceName, match, weights))
+	if err != nil {
+		return err
+	}
+
+	if !r {
+		return errors.New("unknown error: cannot set traffic shifting")
+	}
+
+	return nil
+}
+
+// getTrafficShiftingRule returns the traffic shifting rule of the service with the given matches, or nil if there is none
+func getTrafficShiftingRule(cli cli.CLI, serviceName types.NamespacedName, match []v1alpha3.HTTPMatchRequest) (*TrafficShiftingRule, error) {
+	vservice, err := common.GetVirtualserviceByName(cli, serviceName)
+	if err != nil {
+		if k8serrors.IsNotFound(errors.Cause(err)) {
+			return nil, nil
+		}
+		return nil, errors.WrapIf(err, "could not get virtual service")
+	}
+
+	matches := common.FormatHTTPMatchRequests(match).String()
+	for _, rule := range GetTrafficShiftingRules(vservice) {
+		if rule.Matches.String() == matches {
+			return &rule, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// getSubsetVersion returns the value of the version label selected by a subset, which is used in the metrics
+func getSubsetVersion(cli cli.CLI, serviceName types.NamespacedName, subset string) (string, error) {
+	drule, err := common.GetDestinationRuleByName(cli, serviceName)
+	if err != nil {
+		if k8serrors.IsNotFound(errors.Cause(err)) {
+			return "", errors.Errorf("subset '%s' is not defined for %s", subset, serviceName)
+		}
+		return "", errors.WrapIf(err, "could not get destination rule")
+	}
+
+	for _, s := range drule.Spec.Subsets {
+		if s.Name != subset {
+			continue
+		}
+		if version := s.Labels[versionLabel]; version != "" {
+			return version, nil
+		}
+		return subset, nil
+	}
+
+	return "", errors.Errorf("subset '%s' is not defined for %s", subset, serviceName)
+}
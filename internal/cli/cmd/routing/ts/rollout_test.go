@@ -0,0 +1,84 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ts
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common/commontest"
+)
+
+func TestRolloutCheckMetrics(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+
+	tests := map[string]struct {
+		errorRate   *float64
+		latency     *float64
+		allowNoData bool
+		err         bool
+	}{
+		"healthy":                       {errorRate: value(0.5), latency: value(0.2)},
+		"error rate exceeded":           {errorRate: value(5), latency: value(0.2), err: true},
+		"latency exceeded":              {errorRate: value(0), latency: value(3), err: true},
+		"no requests":                   {err: true},
+		"no requests allowed":           {allowNoData: true},
+		"no latency metrics":            {errorRate: value(0), err: true},
+		"no latency metrics allowed":    {errorRate: value(0), allowNoData: true},
+		"error rate exceeded with flag": {errorRate: value(5), allowNoData: true, err: true},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+
+		t.Run(name, func(t *testing.T) {
+			options := newRolloutOptions()
+			options.serviceName = types.NamespacedName{Name: "movies", Namespace: "backyards-demo"}
+			options.to = "v2"
+			options.allowNoData = test.allowNoData
+
+			promAPI := &commontest.FakePrometheusAPI{}
+			if test.latency != nil {
+				promAPI.Results = append(promAPI.Results, commontest.PrometheusResult{
+					Substr: "histogram_quantile",
+					Vector: model.Vector{commontest.Sample(*test.latency)},
+				})
+			}
+			if test.errorRate != nil {
+				promAPI.Results = append(promAPI.Results, commontest.PrometheusResult{
+					Substr: "istio_requests_total",
+					Vector: model.Vector{commontest.Sample(*test.errorRate)},
+				})
+			}
+			err := (&rolloutCommand{}).checkMetrics(context.Background(), promAPI, options, "v2")
+			if test.err && err == nil {
+				t.Fatal("expected error")
+			}
+			if !test.err && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			for _, query := range promAPI.Queries {
+				if !strings.Contains(query, `reporter="source"`) {
+					t.Errorf("query does not use the metrics of the clients: %s", query)
+				}
+			}
+		})
+	}
+}
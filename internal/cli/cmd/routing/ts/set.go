@@ -15,11 +15,14 @@
 package ts
 
 import (
+	"sort"
+
 	"emperror.dev/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
 
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
 	"github.com/banzaicloud/backyards-cli/pkg/cli"
@@ -31,9 +34,13 @@ type setCommand struct{}
 type setOptions struct {
 	serviceID string
 	subsets   []string
+	matches   []string
+
+	createSubsets bool
 
 	serviceName   types.NamespacedName
 	parsedSubsets parsedSubsets
+	parsedMatches []v1alpha3.HTTPMatchRequest
 }
 
 func newSetOptions() *setOptions {
@@ -45,7 +52,7 @@ func newSetCommand(cli cli.CLI) *cobra.Command {
 	options := newSetOptions()
 
 	cmd := &cobra.Command{
-		Use:           "set [[--service=]namespace/servicename] [[--version=]subset=weight] ...",
+		Use:           "set [[--service=]namespace/servicename] [[--version=]subset=weight] ... [--match=field=value] ...",
 		Short:         "Set traffic shifting rules for a service",
 		Args:          cobra.ArbitraryArgs,
 		SilenceErrors: true,
@@ -78,6 +85,11 @@ func newSetCommand(cli cli.CLI) *cobra.Command {
 				return err
 			}
 
+			options.parsedMatches, err = common.ParseHTTPMatchRequests(options.matches)
+			if err != nil {
+				return err
+			}
+
 			return c.run(cli, options)
 		},
 	}
@@ -85,6 +97,8 @@ func newSetCommand(cli cli.CLI) *cobra.Command {
 	flags := cmd.Flags()
 	flags.StringVar(&options.serviceID, "service", "", "Service name")
 	flags.StringArrayVar(&options.subsets, "subset", []string{}, "Subsets with weights (sum of the weight must add up to 100)")
+	flags.StringArrayVar(&options.matches, "match", []string{}, common.MatchFlagUsage)
+	flags.BoolVar(&options.createSubsets, "create-subsets", options.createSubsets, "Create the missing subsets from the version labels of the pods without asking")
 
 	return cmd
 }
@@ -100,28 +114,28 @@ func (c *setCommand) run(cli cli.CLI, options *setOptions) error {
 		return errors.WrapIf(err, "could not get service")
 	}
 
-	client, err := common.GetGraphQLClient(cli)
+	subsets := make([]string, 0, len(options.parsedSubsets))
+	for subset := range options.parsedSubsets {
+		subsets = append(subsets, subset)
+	}
+	sort.Strings(subsets)
+
+	err = ValidateSubsets(cli, service, subsets, options.createSubsets)
+	if err != nil {
+		return err
+	}
+
+	client, err := cli.GetGraphQLClient()
 	if err != nil {
 		return errors.WrapIf(err, "could not get initialized graphql client")
 	}
 
-	req := graphql.ApplyHTTPRouteRequest{
+	req := NewApplyHTTPRouteRequest(types.NamespacedName{
 		Name:      service.Name,
 		Namespace: service.Namespace,
-		Route:     make([]graphql.HTTPRouteDestination, 0),
-	}
+	}, options.parsedMatches, options.parsedSubsets)
 
-	for subset, weight := range options.parsedSubsets {
-		req.Route = append(req.Route, graphql.HTTPRouteDestination{
-			Destination: graphql.Destination{
-				Host:   service.Name,
-				Subset: subset,
-			},
-			Weight: weight,
-		})
-	}
-
-	r, err := client.ApplyHTTPRoute(req)
+	r, err := client.ApplyHTTPRoute(cli.Context(), req)
 	if err != nil {
 		return err
 	}
@@ -130,7 +144,35 @@ func (c *setCommand) run(cli cli.CLI, options *setOptions) error {
 		return errors.New("unknown error: cannot set traffic shifting")
 	}
 
+	if len(options.parsedMatches) > 0 {
+		log.Infof("traffic shifting for %s matching %s set to %s successfully", options.serviceName, common.FormatHTTPMatchRequests(options.parsedMatches), options.parsedSubsets)
+		return nil
+	}
+
 	log.Infof("traffic shifting for %s set to %s successfully", options.serviceName, options.parsedSubsets)
 
 	return nil
 }
+
+// NewApplyHTTPRouteRequest returns the request which shifts the traffic of a service matching the given match requests
+// between subsets according to their weights
+func NewApplyHTTPRouteRequest(serviceName types.NamespacedName, match []v1alpha3.HTTPMatchRequest, weights map[string]int) graphql.ApplyHTTPRouteRequest {
+	req := graphql.ApplyHTTPRouteRequest{
+		Name:      serviceName.Name,
+		Namespace: serviceName.Namespace,
+		Match:     match,
+		Route:     make([]graphql.HTTPRouteDestination, 0),
+	}
+
+	for subset, weight := range weights {
+		req.Route = append(req.Route, graphql.HTTPRouteDestination{
+			Destination: graphql.Destination{
+				Host:   serviceName.Name,
+				Subset: subset,
+			},
+			Weight: weight,
+		})
+	}
+
+	return req
+}
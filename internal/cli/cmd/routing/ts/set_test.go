@@ -0,0 +1,116 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ts
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli/clitest"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql/graphqltest"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+)
+
+func testObjects() []runtime.Object {
+	return append([]runtime.Object{clitest.NewDemoService(), clitest.NewDemoDestinationRule("v1", "v2")}, clitest.NewDemoPods("v1", "v2", "v3")...)
+}
+
+// checkRoute checks the weights of the routes, which are sent in no particular order, and the number of subsets of the destination rule
+func checkRoute(wantWeights map[string]int, wantSubsets int) func(t *testing.T, mutation graphqltest.Request, k8sClient k8sclient.Client) {
+	return func(t *testing.T, mutation graphqltest.Request, k8sClient k8sclient.Client) {
+		var req graphql.ApplyHTTPRouteRequest
+		if err := mutation.DecodeVariable("input", &req); err != nil {
+			t.Fatal(err)
+		}
+		weights := make(map[string]int)
+		for _, route := range req.Route {
+			if route.Destination.Host != "movies" {
+				t.Errorf("unexpected destination host: %s", route.Destination.Host)
+			}
+			weights[route.Destination.Subset] = route.Weight
+		}
+		if len(weights) != len(wantWeights) {
+			t.Fatalf("unexpected weights: %v", weights)
+		}
+		for subset, weight := range wantWeights {
+			if weights[subset] != weight {
+				t.Errorf("unexpected weights: %v", weights)
+			}
+		}
+
+		var drule v1alpha3.DestinationRule
+		err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: "backyards-demo", Name: "movies"}, &drule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(drule.Spec.Subsets) != wantSubsets {
+			t.Errorf("expected %d subsets, got %+v", wantSubsets, drule.Spec.Subsets)
+		}
+	}
+}
+
+func TestSetCommand(t *testing.T) {
+	clitest.MutationTests{
+		Field:      "applyHTTPRoute",
+		NewCommand: newSetCommand,
+		Objects:    testObjects(),
+		Cases: []clitest.MutationTestCase{
+			{
+				Name:  "shifts traffic between subsets",
+				Args:  []string{"backyards-demo/movies", "v1=30", "v2=70"},
+				Check: checkRoute(map[string]int{"v1": 30, "v2": 70}, 2),
+			},
+			{
+				Name:    "rejects weights not adding up to 100",
+				Args:    []string{"backyards-demo/movies", "v1=30", "v2=30"},
+				WantErr: true,
+			},
+			{
+				Name:    "rejects unknown subsets",
+				Args:    []string{"backyards-demo/movies", "v1=50", "v4=50"},
+				WantErr: true,
+			},
+			{
+				Name:    "rejects undefined subsets without --create-subsets",
+				Args:    []string{"backyards-demo/movies", "v1=50", "v3=50"},
+				WantErr: true,
+			},
+			{
+				Name:  "creates undefined subsets from pod labels",
+				Args:  []string{"backyards-demo/movies", "v1=50", "v3=50", "--create-subsets"},
+				Check: checkRoute(map[string]int{"v1": 50, "v3": 50}, 3),
+			},
+			{
+				Name:    "fails for unknown services",
+				Args:    []string{"backyards-demo/books", "v1=100"},
+				WantErr: true,
+			},
+			{
+				Name: "returns backend errors",
+				Args: []string{"backyards-demo/movies", "v1=100"},
+				Respond: func(s *graphqltest.Server) {
+					s.RespondError("applyHTTPRoute", "FORBIDDEN", "access denied")
+				},
+				WantErr: true,
+				Check:   checkRoute(map[string]int{"v1": 100}, 2),
+			},
+		},
+	}.Run(t)
+}
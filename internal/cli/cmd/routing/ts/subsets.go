@@ -0,0 +1,226 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ts
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/AlecAivazis/survey/v2"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+)
+
+// ValidateSubsets checks that the subsets are defined in the destination rule of the service and
+// warns about subsets without ready pods. Missing subsets can be created from the version labels
+// of the pods behind the service if createMissing is set or the user confirms it.
+func ValidateSubsets(cli cli.CLI, service *corev1.Service, subsets []string, createMissing bool) error {
+	var err error
+
+	serviceName := types.NamespacedName{
+		Namespace: service.Namespace,
+		Name:      service.Name,
+	}
+
+	pods, err := getServicePods(cli, service)
+	if err != nil {
+		return err
+	}
+
+	drule, err := common.GetDestinationRuleByName(cli, serviceName)
+	if err != nil {
+		if !k8serrors.IsNotFound(errors.Cause(err)) {
+			return errors.WrapIf(err, "could not get destination rule")
+		}
+		drule = nil
+	}
+
+	defined := make(map[string]map[string]string)
+	if drule != nil {
+		for _, s := range drule.Spec.Subsets {
+			defined[s.Name] = s.Labels
+		}
+	}
+
+	versions := make(map[string]bool)
+	for _, pod := range pods {
+		if version := pod.Labels[versionLabel]; version != "" {
+			versions[version] = true
+		}
+	}
+
+	unknown := make([]string, 0)
+	creatable := make([]string, 0)
+	for _, subset := range subsets {
+		if _, ok := defined[subset]; ok {
+			continue
+		}
+		if versions[subset] {
+			creatable = append(creatable, subset)
+			continue
+		}
+		unknown = append(unknown, subset)
+	}
+
+	if len(unknown) > 0 {
+		available := make([]string, 0)
+		for subset := range defined {
+			available = append(available, subset)
+		}
+		for version := range versions {
+			if _, ok := defined[version]; !ok {
+				available = append(available, version)
+			}
+		}
+		sort.Strings(available)
+
+		return errors.Errorf("unknown subset(s) for %s: %s (available subsets: %s)",
+			serviceName, strings.Join(unknown, ", "), formatSubsetNames(available))
+	}
+
+	if len(creatable) > 0 {
+		if !createMissing {
+			if !cli.InteractiveTerminal() {
+				return errors.Errorf("subset(s) %s are not defined for %s, use --create-subsets to create them from the version labels of the pods",
+					strings.Join(creatable, ", "), serviceName)
+			}
+
+			err = survey.AskOne(&survey.Confirm{
+				Message: "Subset(s) " + strings.Join(creatable, ", ") + " are not defined, do you want to create them from the version labels of the pods?",
+			}, &createMissing)
+			if err != nil {
+				return errors.WrapIf(err, "could not ask for confirmation")
+			}
+			if !createMissing {
+				return errors.Errorf("subset(s) %s are not defined for %s", strings.Join(creatable, ", "), serviceName)
+			}
+		}
+
+		err = createSubsets(cli, serviceName, drule, creatable)
+		if err != nil {
+			return err
+		}
+
+		for _, subset := range creatable {
+			defined[subset] = map[string]string{versionLabel: subset}
+		}
+	}
+
+	for _, subset := range subsets {
+		if countReadyPods(pods, defined[subset]) == 0 {
+			log.Warnf("subset '%s' of %s does not select any ready pods", subset, serviceName)
+		}
+	}
+
+	return nil
+}
+
+func getServicePods(cli cli.CLI, service *corev1.Service) ([]corev1.Pod, error) {
+	if len(service.Spec.Selector) == 0 {
+		return nil, nil
+	}
+
+	cl, err := cli.GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var pods corev1.PodList
+	err = cl.List(context.Background(), &pods, client.InNamespace(service.Namespace), client.MatchingLabels(service.Spec.Selector))
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list pods of service")
+	}
+
+	return pods.Items, nil
+}
+
+func createSubsets(cli cli.CLI, serviceName types.NamespacedName, drule *v1alpha3.DestinationRule, subsets []string) error {
+	cl, err := cli.GetK8sClient()
+	if err != nil {
+		return err
+	}
+
+	newSubsets := make([]v1alpha3.Subset, 0, len(subsets))
+	for _, subset := range subsets {
+		newSubsets = append(newSubsets, v1alpha3.Subset{
+			Name: subset,
+			Labels: map[string]string{
+				versionLabel: subset,
+			},
+		})
+	}
+
+	if drule == nil {
+		drule = &v1alpha3.DestinationRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      serviceName.Name,
+				Namespace: serviceName.Namespace,
+			},
+			Spec: v1alpha3.DestinationRuleSpec{
+				Host:    serviceName.Name,
+				Subsets: newSubsets,
+			},
+		}
+		err = cl.Create(context.Background(), drule)
+	} else {
+		drule.Spec.Subsets = append(drule.Spec.Subsets, newSubsets...)
+		err = cl.Update(context.Background(), drule)
+	}
+	if err != nil {
+		return errors.WrapIf(err, "could not save destination rule")
+	}
+
+	log.Infof("subset(s) %s created for %s", strings.Join(subsets, ", "), serviceName)
+
+	return nil
+}
+
+func countReadyPods(pods []corev1.Pod, subsetLabels map[string]string) int {
+	selector := labels.SelectorFromSet(subsetLabels)
+
+	count := 0
+	for _, pod := range pods {
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+				count++
+				break
+			}
+		}
+	}
+
+	return count
+}
+
+func formatSubsetNames(subsets []string) string {
+	if len(subsets) == 0 {
+		return "none"
+	}
+
+	return strings.Join(subsets, ", ")
+}
@@ -0,0 +1,174 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+	"github.com/banzaicloud/backyards-cli/pkg/output"
+)
+
+const defaultMetricsInterval = time.Minute
+
+type Service struct {
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace"`
+	Ports     []graphql.ServicePort  `json:"ports"`
+	Metrics   Metrics                `json:"metrics"`
+	Workloads []graphql.MeshWorkload `json:"workloads,omitempty"`
+}
+
+// Metrics holds the rate, errors and duration (RED) metrics of a service,
+// the error rate is a percentage and the latencies are in milliseconds
+type Metrics struct {
+	RequestRate float64 `json:"requestRate"`
+	ErrorRate   float64 `json:"errorRate"`
+	LatencyP50  float64 `json:"latencyP50"`
+	LatencyP95  float64 `json:"latencyP95"`
+	LatencyP99  float64 `json:"latencyP99"`
+}
+
+// FormattedPorts is used by the table output
+func (s Service) FormattedPorts() string {
+	ports := make([]string, 0, len(s.Ports))
+	for _, port := range s.Ports {
+		if port.Name != "" {
+			ports = append(ports, fmt.Sprintf("%s:%d", port.Name, port.Port))
+			continue
+		}
+		ports = append(ports, fmt.Sprintf("%d", port.Port))
+	}
+
+	return strings.Join(ports, ",")
+}
+
+type Pod struct {
+	Workload string `json:"workload"`
+	graphql.MeshPod
+}
+
+func newService(service graphql.MeshService, metrics map[types.NamespacedName]Metrics) Service {
+	return Service{
+		Name:      service.Name,
+		Namespace: service.Namespace,
+		Ports:     service.Ports,
+		Metrics:   metrics[types.NamespacedName{Name: service.Name, Namespace: service.Namespace}],
+	}
+}
+
+// getServiceMetrics returns the metrics of every service of a namespace, or of every namespace if it is empty.
+// The metrics are queried from the Prometheus of Backyards with the queries of the base graph template,
+// each of them returns the values of every service at once.
+func getServiceMetrics(ctx context.Context, api promv1.API, namespace string, interval time.Duration) (map[types.NamespacedName]Metrics, error) {
+	filters := []string{`reporter="destination"`}
+	if namespace != "" {
+		filters = append(filters, fmt.Sprintf("destination_service_namespace=%q", namespace))
+	}
+	filter := strings.Join(filters, ",")
+	rateInterval := model.Duration(interval).String()
+	latency := func(quantile string) string {
+		return fmt.Sprintf("histogram_quantile(%s, sum(rate(istio_backyards_request_duration_seconds_bucket{%s}[%s])) by (destination_service_namespace, destination_service_name, le)) * 1000",
+			quantile, filter, rateInterval)
+	}
+
+	metrics := make(map[types.NamespacedName]*Metrics)
+	for _, q := range []struct {
+		query string
+		set   func(m *Metrics, value float64)
+	}{
+		{
+			query: fmt.Sprintf("sum(rate(istio_requests_total{%s}[%s])) by (destination_service_namespace, destination_service_name)", filter, rateInterval),
+			set:   func(m *Metrics, value float64) { m.RequestRate = value },
+		},
+		{
+			query: fmt.Sprintf(`sum(rate(istio_requests_total{%s,response_code=~"5.."}[%s])) by (destination_service_namespace, destination_service_name)`, filter, rateInterval),
+			// the rate of failed requests, turned into a percentage once every request rate is known
+			set: func(m *Metrics, value float64) { m.ErrorRate = value },
+		},
+		{
+			query: latency("0.50"),
+			set:   func(m *Metrics, value float64) { m.LatencyP50 = value },
+		},
+		{
+			query: latency("0.95"),
+			set:   func(m *Metrics, value float64) { m.LatencyP95 = value },
+		},
+		{
+			query: latency("0.99"),
+			set:   func(m *Metrics, value float64) { m.LatencyP99 = value },
+		},
+	} {
+		vector, err := common.QueryPrometheusVector(ctx, api, q.query)
+		if err != nil {
+			return nil, errors.WrapIf(err, "could not get service metrics")
+		}
+
+		for _, sample := range vector {
+			serviceName := types.NamespacedName{
+				Name:      string(sample.Metric["destination_service_name"]),
+				Namespace: string(sample.Metric["destination_service_namespace"]),
+			}
+			if _, ok := metrics[serviceName]; !ok {
+				metrics[serviceName] = &Metrics{}
+			}
+			q.set(metrics[serviceName], float64(sample.Value))
+		}
+	}
+
+	result := make(map[types.NamespacedName]Metrics, len(metrics))
+	for serviceName, m := range metrics {
+		errorRate := 0.0
+		if m.RequestRate > 0 {
+			errorRate = m.ErrorRate / m.RequestRate * 100
+		}
+		result[serviceName] = Metrics{
+			RequestRate: common.Round(m.RequestRate),
+			ErrorRate:   common.Round(errorRate),
+			LatencyP50:  common.Round(m.LatencyP50),
+			LatencyP95:  common.Round(m.LatencyP95),
+			LatencyP99:  common.Round(m.LatencyP99),
+		}
+	}
+
+	return result, nil
+}
+
+func outputServices(cli cli.CLI, services []Service) error {
+	ctx := &output.Context{
+		Out:     cli.Out(),
+		Color:   cli.Color(),
+		Format:  cli.OutputFormat(),
+		Fields:  []string{"Namespace", "Name", "FormattedPorts", "Metrics.RequestRate", "Metrics.ErrorRate", "Metrics.LatencyP95"},
+		Headers: []string{"Namespace", "Name", "Ports", "Requests/s", "Errors %", "P95 latency (ms)"},
+	}
+
+	err := output.Output(ctx, services)
+	if err != nil {
+		return errors.WrapIf(err, "could not produce output")
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common/commontest"
+)
+
+func TestGetServiceMetrics(t *testing.T) {
+	service := func(name string) []string {
+		return []string{"destination_service_namespace", "backyards-demo", "destination_service_name", name}
+	}
+
+	promAPI := &commontest.FakePrometheusAPI{
+		Results: []commontest.PrometheusResult{
+			{Substr: "histogram_quantile(0.50", Vector: model.Vector{commontest.Sample(5.5, service("movies")...)}},
+			{Substr: "histogram_quantile(0.95", Vector: model.Vector{commontest.Sample(35.2219, service("movies")...)}},
+			{Substr: "histogram_quantile(0.99", Vector: model.Vector{commontest.Sample(50, service("movies")...)}},
+			{Substr: `response_code=~"5.."`, Vector: model.Vector{commontest.Sample(0.2, service("movies")...)}},
+			{Substr: "istio_requests_total", Vector: model.Vector{
+				commontest.Sample(4.9, service("movies")...),
+				commontest.Sample(2.45, service("analytics")...),
+			}},
+		},
+	}
+
+	metrics, err := getServiceMetrics(context.Background(), promAPI, "backyards-demo", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[types.NamespacedName]Metrics{
+		{Name: "movies", Namespace: "backyards-demo"}: {
+			RequestRate: 4.9,
+			ErrorRate:   4.082,
+			LatencyP50:  5.5,
+			LatencyP95:  35.222,
+			LatencyP99:  50,
+		},
+		{Name: "analytics", Namespace: "backyards-demo"}: {
+			RequestRate: 2.45,
+		},
+	}
+	if !reflect.DeepEqual(metrics, expected) {
+		t.Errorf("unexpected metrics\ngot : %+v\nwant: %+v", metrics, expected)
+	}
+
+	if len(promAPI.Queries) != 5 {
+		t.Errorf("expected a query per metric, got %d", len(promAPI.Queries))
+	}
+	for _, query := range promAPI.Queries {
+		if !strings.Contains(query, `destination_service_namespace="backyards-demo"`) {
+			t.Errorf("query is not scoped to the namespace: %s", query)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+	"github.com/banzaicloud/backyards-cli/pkg/output"
+)
+
+type getCommand struct{}
+
+type getOptions struct {
+	serviceID string
+	interval  time.Duration
+
+	serviceName types.NamespacedName
+}
+
+func newGetOptions() *getOptions {
+	return &getOptions{
+		interval: defaultMetricsInterval,
+	}
+}
+
+func newGetCommand(cli cli.CLI) *cobra.Command {
+	c := &getCommand{}
+	options := newGetOptions()
+
+	cmd := &cobra.Command{
+		Use:           "get [[--service=]namespace/servicename]",
+		Short:         "Show the metrics, workloads and pods of a service",
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			if len(args) > 0 {
+				options.serviceID = args[0]
+			}
+
+			if options.serviceID == "" {
+				return errors.New("service must be specified")
+			}
+
+			options.serviceName, err = common.ParseServiceID(options.serviceID)
+			if err != nil {
+				return err
+			}
+
+			if options.interval < time.Second {
+				return errors.New("interval must be at least 1s")
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.serviceID, "service", "", "Service name")
+	flags.DurationVar(&options.interval, "interval", options.interval, "Time range to calculate the metrics over")
+
+	return cmd
+}
+
+func (c *getCommand) run(cli cli.CLI, options *getOptions) error {
+	var err error
+
+	client, err := cli.GetGraphQLClient()
+	if err != nil {
+		return errors.WrapIf(err, "could not get initialized graphql client")
+	}
+
+	meshService, err := client.Service(cli.Context(), graphql.ServiceRequest{
+		Name:      options.serviceName.Name,
+		Namespace: options.serviceName.Namespace,
+	})
+	if err != nil {
+		if graphql.IsNotFound(err) {
+			return err
+		}
+		return errors.WrapIf(err, "could not get service")
+	}
+
+	promAPI, err := common.GetPrometheusAPI(cli)
+	if err != nil {
+		return errors.WrapIf(err, "could not get initialized prometheus client")
+	}
+
+	metrics, err := getServiceMetrics(cli.Context(), promAPI, options.serviceName.Namespace, options.interval)
+	if err != nil {
+		return err
+	}
+
+	service := newService(*meshService, metrics)
+
+	service.Workloads, err = client.Workloads(cli.Context(), graphql.WorkloadsRequest{
+		Namespace: options.serviceName.Namespace,
+		Service:   options.serviceName.Name,
+	})
+	if err != nil {
+		return errors.WrapIf(err, "could not get workloads of service")
+	}
+
+	if cli.OutputFormat() != output.OutputFormatTable {
+		return output.Output(&output.Context{
+			Out:    cli.Out(),
+			Format: cli.OutputFormat(),
+		}, service)
+	}
+
+	err = outputServices(cli, []Service{service})
+	if err != nil {
+		return err
+	}
+
+	pods := make([]Pod, 0)
+	for _, workload := range service.Workloads {
+		for _, pod := range workload.Pods {
+			pods = append(pods, Pod{
+				Workload: workload.Name,
+				MeshPod:  pod,
+			})
+		}
+	}
+
+	ctx := &output.Context{
+		Out:     cli.Out(),
+		Color:   cli.Color(),
+		Format:  cli.OutputFormat(),
+		Fields:  []string{"Workload", "Name", "Phase", "Ready", "Restarts", "NodeName"},
+		Headers: []string{"Workload", "Pod", "Phase", "Ready", "Restarts", "Node"},
+	}
+
+	err = output.Output(ctx, pods)
+	if err != nil {
+		return errors.WrapIf(err, "could not produce output")
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"sort"
+	"time"
+
+	"emperror.dev/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing/common"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+)
+
+type listCommand struct{}
+
+type listOptions struct {
+	namespace     string
+	allNamespaces bool
+	interval      time.Duration
+}
+
+func newListOptions() *listOptions {
+	return &listOptions{
+		interval: defaultMetricsInterval,
+	}
+}
+
+func newListCommand(cli cli.CLI) *cobra.Command {
+	c := &listCommand{}
+	options := newListOptions()
+
+	cmd := &cobra.Command{
+		Use:   "list [namespace]",
+		Short: "List the services of the mesh with their request rate, error rate and latency",
+		Long: `List the services of the mesh with their request rate, error rate and latency.
+
+Without arguments the services of the current namespace are listed.`,
+		Aliases:       []string{"ls"},
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			if len(args) > 0 {
+				options.namespace = args[0]
+			}
+
+			if options.namespace != "" && options.allNamespaces {
+				return errors.New("namespace cannot be specified together with --all-namespaces")
+			}
+
+			if options.interval < time.Second {
+				return errors.New("interval must be at least 1s")
+			}
+
+			if options.namespace == "" && !options.allNamespaces {
+				options.namespace, err = k8sclient.GetNamespaceWithContext(viper.GetString("kubeconfig"), viper.GetString("kubecontext"))
+				if err != nil {
+					return errors.WrapIf(err, "could not get namespace from kubeconfig")
+				}
+			}
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&options.allNamespaces, "all-namespaces", "A", options.allNamespaces, "List services across all namespaces")
+	flags.DurationVar(&options.interval, "interval", options.interval, "Time range to calculate the metrics over")
+
+	return cmd
+}
+
+func (c *listCommand) run(cli cli.CLI, options *listOptions) error {
+	var err error
+
+	client, err := cli.GetGraphQLClient()
+	if err != nil {
+		return errors.WrapIf(err, "could not get initialized graphql client")
+	}
+
+	req := graphql.ServicesRequest{}
+	if options.namespace != "" {
+		req.Namespaces = []string{options.namespace}
+	}
+
+	meshServices, err := client.Services(cli.Context(), req)
+	if err != nil {
+		return errors.WrapIf(err, "could not list services")
+	}
+
+	if len(meshServices) == 0 {
+		if options.namespace != "" {
+			log.Infof("no services found in namespace %s", options.namespace)
+		} else {
+			log.Info("no services found")
+		}
+		return nil
+	}
+
+	promAPI, err := common.GetPrometheusAPI(cli)
+	if err != nil {
+		return errors.WrapIf(err, "could not get initialized prometheus client")
+	}
+
+	metrics, err := getServiceMetrics(cli.Context(), promAPI, options.namespace, options.interval)
+	if err != nil {
+		return err
+	}
+
+	services := make([]Service, 0, len(meshServices))
+	for _, meshService := range meshServices {
+		services = append(services, newService(meshService, metrics))
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Namespace != services[j].Namespace {
+			return services[i].Namespace < services[j].Namespace
+		}
+		return services[i].Name < services[j].Name
+	})
+
+	return outputServices(cli, services)
+}
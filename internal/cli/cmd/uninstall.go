@@ -20,15 +20,19 @@ import (
 
 	"emperror.dev/errors"
 	"github.com/spf13/cobra"
+	"istio.io/operator/pkg/object"
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/canary"
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/certmanager"
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/demoapp"
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/istio"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/util"
 	"github.com/banzaicloud/backyards-cli/pkg/cli"
 	"github.com/banzaicloud/backyards-cli/pkg/helm"
 	"github.com/banzaicloud/backyards-cli/pkg/k8s"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+	"github.com/banzaicloud/backyards-cli/pkg/release"
 )
 
 type uninstallCommand struct{}
@@ -37,6 +41,7 @@ type UninstallOptions struct {
 	releaseName    string
 	istioNamespace string
 	dumpResources  bool
+	dryRun         k8s.DryRunStrategy
 
 	uninstallCanary      bool
 	uninstallDemoapp     bool
@@ -78,6 +83,7 @@ It can only dump the removable resources with the '--dump-resources' option.`,
 	cmd.Flags().StringVar(&options.releaseName, "release-name", "backyards", "Name of the release")
 	cmd.Flags().StringVar(&options.istioNamespace, "istio-namespace", "istio-system", "Namespace of Istio sidecar injector")
 	cmd.Flags().BoolVarP(&options.dumpResources, "dump-resources", "d", false, "Dump resources to stdout instead of applying them")
+	options.dryRun.AddFlag(cmd.Flags())
 
 	cmd.Flags().BoolVar(&options.uninstallCanary, "uninstall-canary", false, "Uninstall Canary feature as well")
 	cmd.Flags().BoolVar(&options.uninstallDemoapp, "uninstall-demoapp", false, "Uninstall Demo application as well")
@@ -89,24 +95,23 @@ It can only dump the removable resources with the '--dump-resources' option.`,
 }
 
 func (c *uninstallCommand) run(cli cli.CLI, options *UninstallOptions) error {
-	values, err := getValues(options.releaseName, options.istioNamespace, nil)
+	client, err := cli.GetK8sClient()
 	if err != nil {
 		return err
 	}
 
-	objects, err := getBackyardsObjects(values)
+	objects, err := c.getObjects(client, options)
 	if err != nil {
 		return err
 	}
 
 	objects.Sort(helm.UninstallObjectOrder())
 
-	if !options.dumpResources {
-		client, err := cli.GetK8sClient()
-		if err != nil {
-			return err
-		}
+	if options.dryRun.Enabled() && !options.dumpResources {
+		return util.DryRun(cli, client, options.dryRun, nil, objects)
+	}
 
+	if !options.dumpResources {
 		err = k8s.DeleteResources(client, objects, k8s.WaitForResourceConditions(wait.Backoff{
 			Duration: time.Second * 5,
 			Factor:   1,
@@ -116,6 +121,12 @@ func (c *uninstallCommand) run(cli cli.CLI, options *UninstallOptions) error {
 		if err != nil {
 			return errors.WrapIf(err, "could not delete k8s resources")
 		}
+
+		err = util.GetReleaseStore(client).Delete(options.releaseName)
+		if err != nil {
+			return errors.WrapIf(err, "could not delete release records")
+		}
+
 		return nil
 	}
 
@@ -128,6 +139,25 @@ func (c *uninstallCommand) run(cli cli.CLI, options *UninstallOptions) error {
 	return nil
 }
 
+// getObjects returns the objects of the last revision of the release, or the objects rendered with the default values
+// if the release was installed before the CLI kept records
+func (c *uninstallCommand) getObjects(client k8sclient.Client, options *UninstallOptions) (object.K8sObjects, error) {
+	last, err := util.GetReleaseStore(client).Last(options.releaseName)
+	if err == nil {
+		return util.GetReleaseObjects(last)
+	}
+	if !release.IsNotFound(err) {
+		return nil, err
+	}
+
+	_, rawValues, err := getValues(options.releaseName, options.istioNamespace, "", helm.ValueOverrides{}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return getBackyardsObjects(rawValues, false)
+}
+
 func (c *uninstallCommand) runSubcommands(cli cli.CLI, options *UninstallOptions) error {
 	var err error
 	var scmd *cobra.Command
@@ -137,6 +167,7 @@ func (c *uninstallCommand) runSubcommands(cli cli.CLI, options *UninstallOptions
 		if options.dumpResources {
 			scmdOptions.DumpResources = true
 		}
+		scmdOptions.DryRun = options.dryRun
 		scmd = demoapp.NewUninstallCommand(cli, scmdOptions)
 		err = scmd.RunE(scmd, nil)
 		if err != nil {
@@ -149,6 +180,7 @@ func (c *uninstallCommand) runSubcommands(cli cli.CLI, options *UninstallOptions
 		if options.dumpResources {
 			scmdOptions.DumpResources = true
 		}
+		scmdOptions.DryRun = options.dryRun
 		scmd = canary.NewUninstallCommand(cli, scmdOptions)
 		err = scmd.RunE(scmd, nil)
 		if err != nil {
@@ -161,6 +193,7 @@ func (c *uninstallCommand) runSubcommands(cli cli.CLI, options *UninstallOptions
 		if options.dumpResources {
 			scmdOptions.DumpResources = true
 		}
+		scmdOptions.DryRun = options.dryRun
 		scmd = certmanager.NewUninstallCommand(cli, scmdOptions)
 		err = scmd.RunE(scmd, nil)
 		if err != nil {
@@ -173,6 +206,7 @@ func (c *uninstallCommand) runSubcommands(cli cli.CLI, options *UninstallOptions
 		if options.dumpResources {
 			scmdOptions.DumpResources = true
 		}
+		scmdOptions.DryRun = options.dryRun
 		scmd = istio.NewUninstallCommand(cli, scmdOptions)
 		err = scmd.RunE(scmd, nil)
 		if err != nil {
@@ -0,0 +1,306 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/AlecAivazis/survey/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/ttacon/chalk"
+	"istio.io/operator/pkg/object"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/istio"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/util"
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/helm"
+	"github.com/banzaicloud/backyards-cli/pkg/k8s"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+	"github.com/banzaicloud/backyards-cli/pkg/release"
+)
+
+type upgradeCommand struct{}
+
+type UpgradeOptions struct {
+	releaseName        string
+	istioNamespace     string
+	disableCertManager bool
+	disableAuditSink   bool
+	yes                bool
+	prune              bool
+	resetValues        bool
+	dryRun             k8s.DryRunStrategy
+
+	values helm.ValueOverrides
+	// flagValues are the values of the explicitly set flags in key=value format, they take precedence over the reused values
+	flagValues []string
+}
+
+// applyOptions controls how applyChanges applies the objects of a release
+type applyOptions struct {
+	yes    bool
+	prune  bool
+	dryRun k8s.DryRunStrategy
+	// labels are the ownership labels of the objects, the objects with these labels which are not rendered any more are pruned
+	labels map[string]string
+}
+
+func NewUpgradeOptions() *UpgradeOptions {
+	return &UpgradeOptions{
+		releaseName:    defaultReleaseName,
+		istioNamespace: istio.DefaultNamespace,
+		prune:          true,
+	}
+}
+
+func NewUpgradeCommand(cli cli.CLI) *cobra.Command {
+	c := &upgradeCommand{}
+	options := NewUpgradeOptions()
+
+	cmd := &cobra.Command{
+		Use:   "upgrade [flags]",
+		Args:  cobra.NoArgs,
+		Short: "Upgrade Backyards",
+		Long: `Upgrades Backyards to the version of the CLI.
+
+The resources of the new version are compared with the ones in the cluster and the differences
+are shown before applying them. Resources of the previous version which are not part of the new
+one are deleted, so are the resources labeled as part of the release which are not rendered any more,
+unless '--prune=false' is set. The upgrade is recorded as a new revision of the release.
+
+The values given for the last revision are reused on top of the defaults of the new version,
+the given values and flags are merged on top of them. With '--reset-values' the resources are
+rendered with the defaults and the given values only.`,
+		Example: `  # Show the changes and upgrade after confirmation.
+  backyards upgrade
+
+  # Upgrade with custom values without confirmation.
+  backyards upgrade -f values.yaml --yes
+
+  # Upgrade with the default values instead of the ones given for the last revision.
+  backyards upgrade --reset-values`,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.flagValues = getFlagValues(cmd.Flags(), options.disableCertManager, options.disableAuditSink)
+
+			return c.run(cli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.releaseName, "release-name", options.releaseName, "Name of the release")
+	flags.StringVar(&options.istioNamespace, "istio-namespace", options.istioNamespace, "Namespace of Istio sidecar injector")
+	flags.BoolVar(&options.disableCertManager, "disable-cert-manager", options.disableCertManager, "Disable dependency on cert-manager and on it's resources")
+	flags.BoolVar(&options.disableAuditSink, "disable-auditsink", options.disableAuditSink, "Disable deploying the auditsink service and sending audit logs over http")
+	flags.BoolVarP(&options.yes, "yes", "y", options.yes, "Upgrade without asking for confirmation")
+	flags.BoolVar(&options.prune, "prune", options.prune, "Delete the previously applied resources of the release which are not rendered any more")
+	flags.BoolVar(&options.resetValues, "reset-values", options.resetValues, "Render with the default values instead of reusing the values given for the last revision")
+	options.dryRun.AddFlag(flags)
+	options.values.AddFlags(flags)
+
+	return cmd
+}
+
+func (c *upgradeCommand) run(cli cli.CLI, options *UpgradeOptions) error {
+	client, err := cli.GetK8sClient()
+	if err != nil {
+		return err
+	}
+
+	last, err := util.GetReleaseStore(client).Last(options.releaseName)
+	if err != nil && !release.IsNotFound(err) {
+		return err
+	}
+
+	// only the values given by the user are reused, so the chart defaults of the new version, e.g. image tags, take effect
+	base := ""
+	if last != nil && !options.resetValues {
+		base = last.UserValues
+		log.Infof("reusing the values given for revision %d of release %s", last.Revision, last.Name)
+	}
+
+	userValues, err := getUserValues(base, options.flagValues, options.values)
+	if err != nil {
+		return err
+	}
+
+	if last != nil && options.resetValues && last.UserValues != "" && last.UserValues != userValues {
+		log.Warnf("the values given for revision %d of release %s are not reused since --reset-values is set", last.Revision, last.Name)
+	}
+
+	values, rawValues, err := getValues(options.releaseName, options.istioNamespace, userValues, helm.ValueOverrides{}, func(values *Values) {
+		values.CertManager.Enabled = !options.disableCertManager
+		values.AuditSink.Enabled = !options.disableAuditSink
+	})
+	if err != nil {
+		return err
+	}
+
+	objects, err := getBackyardsObjects(rawValues, true)
+	if err != nil {
+		return err
+	}
+	objects.Sort(helm.InstallObjectOrder())
+
+	labels := k8s.OwnershipLabels(options.releaseName, componentName, viper.GetString("backyards.namespace"))
+	k8s.LabelResources(objects, labels)
+
+	previous, err := getPreviousObjects(client, options.releaseName, objects)
+	if err != nil {
+		return err
+	}
+
+	applied, err := applyChanges(cli, client, objects, previous, applyOptions{
+		yes:    options.yes,
+		prune:  options.prune,
+		dryRun: options.dryRun,
+		labels: labels,
+	})
+	if err != nil || !applied {
+		return err
+	}
+
+	_, err = util.RecordRelease(cli, client, options.releaseName, objects, rawValues, userValues)
+	if err != nil {
+		return err
+	}
+
+	return setTracingAddress(cli, values)
+}
+
+// applyChanges shows the differences between the objects and the cluster and applies them after confirmation.
+// Previous objects which are not part of the objects are deleted, and so are the objects with the ownership labels
+// if pruning is enabled. It returns false if there was nothing to apply, or nothing was applied in dry run mode.
+// With the server dry run strategy the changes are sent to the API server in dry run mode as well.
+func applyChanges(cli cli.CLI, client k8sclient.Client, objects, previous object.K8sObjects, options applyOptions) (bool, error) {
+	if options.prune {
+		all := append(append(object.K8sObjects{}, objects...), previous...)
+		pruned, err := k8s.PruneResources(client, all, k8s.GroupVersionKinds(all), options.labels, true)
+		if err != nil {
+			return false, err
+		}
+		// the pruned objects are deleted just like the previous objects which are not part of the objects any more
+		previous = append(append(object.K8sObjects{}, previous...), pruned...)
+	}
+
+	diffs, err := k8s.DiffResources(client, objects, previous)
+	if err != nil {
+		return false, errors.WrapIf(err, "could not compare resources with the cluster")
+	}
+
+	removed := make(object.K8sObjects, 0)
+	changes := 0
+	for _, diff := range diffs {
+		if diff.Action == k8s.DiffActionUnchanged {
+			continue
+		}
+		changes++
+		if diff.Action == k8s.DiffActionDelete {
+			removed = append(removed, diff.Object)
+		}
+		writeDiff(cli.Out(), diff.Diff, cli.Color())
+	}
+
+	if changes == 0 {
+		log.Info("no changes to apply")
+		return false, nil
+	}
+	log.Info(summarizeDiffs(diffs))
+
+	if options.dryRun == k8s.DryRunServer {
+		return false, util.DryRun(cli, client, options.dryRun, objects, removed)
+	}
+	if options.dryRun.Enabled() {
+		return false, nil
+	}
+
+	if !options.yes {
+		if !cli.InteractiveTerminal() {
+			return false, errors.New("changes must be confirmed with --yes in non-interactive mode")
+		}
+
+		confirmed := false
+		err = survey.AskOne(&survey.Confirm{Message: "Do you want to apply the changes?"}, &confirmed)
+		if err != nil {
+			return false, errors.WrapIf(err, "could not ask for confirmation")
+		}
+		if !confirmed {
+			return false, errors.New("cancelled")
+		}
+	}
+
+	err = k8s.ApplyResources(client, objects)
+	if err != nil {
+		return false, err
+	}
+
+	removed.Sort(helm.UninstallObjectOrder())
+	err = k8s.DeleteResources(client, removed)
+	if err != nil {
+		return false, err
+	}
+
+	err = k8s.WaitForResourcesConditions(client, k8s.NamesWithGVKFromK8sObjects(objects), wait.Backoff{
+		Duration: time.Second * 5,
+		Factor:   1,
+		Jitter:   0,
+		Steps:    24,
+	}, k8s.ExistsConditionCheck, k8s.ReadyReplicasConditionCheck)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// summarizeDiffs returns the number of objects per action, e.g. "2 to create, 1 to update, 0 to delete, 30 unchanged"
+func summarizeDiffs(diffs []k8s.ObjectDiff) string {
+	counts := make(map[k8s.DiffAction]int)
+	for _, diff := range diffs {
+		counts[diff.Action]++
+	}
+
+	return fmt.Sprintf("%d to create, %d to update, %d to delete, %d unchanged",
+		counts[k8s.DiffActionCreate], counts[k8s.DiffActionUpdate], counts[k8s.DiffActionDelete], counts[k8s.DiffActionUnchanged])
+}
+
+// writeDiff writes a diff in unified format, the added and removed lines are colored if color is set
+func writeDiff(out io.Writer, diff string, color bool) {
+	if !color {
+		fmt.Fprint(out, diff)
+		return
+	}
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			line = chalk.Bold.TextStyle(line)
+		case strings.HasPrefix(line, "+"):
+			line = chalk.Green.Color(line)
+		case strings.HasPrefix(line, "-"):
+			line = chalk.Red.Color(line)
+		case strings.HasPrefix(line, "@@"):
+			line = chalk.Cyan.Color(line)
+		}
+		fmt.Fprint(out, line)
+	}
+}
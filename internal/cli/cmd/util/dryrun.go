@@ -0,0 +1,86 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"emperror.dev/errors"
+	"istio.io/operator/pkg/object"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/k8s"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+	"github.com/banzaicloud/backyards-cli/pkg/output"
+)
+
+type dryRunResult struct {
+	Name    string `json:"name"`
+	Result  string `json:"result"`
+	Message string `json:"message,omitempty"`
+}
+
+// DryRun reports what applying the objects and deleting the removed objects would do, without changing anything in
+// the cluster. It fails if any of the objects would be rejected by the API server.
+func DryRun(cli cli.CLI, client k8sclient.Client, strategy k8s.DryRunStrategy, objects, removed object.K8sObjects) error {
+	results, err := k8s.DryRunResources(client, objects, strategy)
+	if err != nil {
+		return errors.WrapIf(err, "could not dry run resources")
+	}
+
+	deletions, err := k8s.DryRunDeleteResources(client, removed, strategy)
+	if err != nil {
+		return errors.WrapIf(err, "could not dry run deletion of resources")
+	}
+
+	return ReportDryRun(cli, append(results, deletions...))
+}
+
+// ReportDryRun writes the results of a dry run in the output format of the CLI, it fails if any of the objects
+// would be rejected
+func ReportDryRun(cli cli.CLI, results []k8s.DryRunResult) error {
+	data := make([]dryRunResult, 0, len(results))
+	rejected := 0
+	for _, r := range results {
+		item := dryRunResult{
+			Name:    r.Name,
+			Result:  string(r.Action),
+			Message: r.Warning,
+		}
+		if r.Rejected() {
+			rejected++
+			item.Result = "rejected"
+			item.Message = r.Error.Error()
+		}
+		data = append(data, item)
+	}
+
+	ctx := &output.Context{
+		Out:     cli.Out(),
+		Color:   cli.Color(),
+		Format:  cli.OutputFormat(),
+		Fields:  []string{"Name", "Result", "Message"},
+		Headers: []string{"Name", "Result", "Message"},
+	}
+
+	err := output.Output(ctx, data)
+	if err != nil {
+		return errors.WrapIf(err, "could not produce output")
+	}
+
+	if rejected > 0 {
+		return errors.NewWithDetails("resources would be rejected by the API server", "rejected", rejected)
+	}
+
+	return nil
+}
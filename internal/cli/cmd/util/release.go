@@ -0,0 +1,82 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"emperror.dev/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"istio.io/operator/pkg/object"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/helm"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+	"github.com/banzaicloud/backyards-cli/pkg/release"
+)
+
+// GetReleaseStore returns the store of the release records, which are kept in the Backyards namespace for every component
+func GetReleaseStore(client k8sclient.Client) *release.Store {
+	return release.NewStore(client, viper.GetString("backyards.namespace"))
+}
+
+// RecordRelease stores a new revision of the release with the applied objects, the values they were rendered with
+// and the values given by the user, which are reused on upgrade
+func RecordRelease(cli cli.CLI, client k8sclient.Client, releaseName string, objects object.K8sObjects, rawValues, userValues string) (*release.Release, error) {
+	manifest, err := objects.YAMLManifest()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not render YAML manifest")
+	}
+
+	r := &release.Release{
+		Name:       releaseName,
+		Version:    cli.GetRootCommand().Version,
+		Manifest:   manifest,
+		Values:     rawValues,
+		UserValues: userValues,
+	}
+
+	err = GetReleaseStore(client).Create(r)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not record release")
+	}
+	log.Debugf("recorded revision %d of release %s", r.Revision, r.Name)
+
+	return r, nil
+}
+
+// GetReleaseObjects returns the objects of the recorded release in install order
+func GetReleaseObjects(r *release.Release) (object.K8sObjects, error) {
+	objects, err := object.ParseK8sObjectsFromYAMLManifest(r.Manifest)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not parse manifest of release", "name", r.Name, "revision", r.Revision)
+	}
+	objects.Sort(helm.InstallObjectOrder())
+
+	return objects, nil
+}
+
+// GetInstalledObjects returns the objects of the last revision of the release. Releases installed before the CLI
+// kept records have no revisions, for those the objects returned by render are used.
+func GetInstalledObjects(client k8sclient.Client, releaseName string, render func() (object.K8sObjects, error)) (object.K8sObjects, error) {
+	last, err := GetReleaseStore(client).Last(releaseName)
+	if err == nil {
+		return GetReleaseObjects(last)
+	}
+	if !release.IsNotFound(err) {
+		return nil, err
+	}
+
+	return render()
+}
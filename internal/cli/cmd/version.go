@@ -16,7 +16,6 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
-	"net/http"
 
 	"github.com/spf13/cobra"
 
@@ -84,17 +83,13 @@ func (c *versionCommand) run(cli cli.CLI, options *versionOptions) {
 }
 
 func getAPIVersion(cli cli.CLI, versionEndpoint string) string {
-	pf, err := cli.GetPortforwardForIGW(0)
+	endpoint, err := cli.GetEndpoint(0)
 	if err != nil {
 		return defaultVersionString
 	}
+	defer endpoint.Close()
 
-	err = pf.Run()
-	if err != nil {
-		return defaultVersionString
-	}
-
-	resp, err := http.Get(pf.GetURL(versionEndpoint))
+	resp, err := endpoint.HTTPClient().Get(endpoint.URLForPath(versionEndpoint))
 	if err != nil {
 		return defaultVersionString
 	}
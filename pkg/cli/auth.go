@@ -0,0 +1,163 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/banzaicloud/backyards-cli/pkg/k8s"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+)
+
+const (
+	// AuthModeServiceAccount authenticates to the Backyards API with a token of the backyards service account
+	AuthModeServiceAccount = "service-account"
+	// AuthModeUser authenticates to the Backyards API as the calling user with the bearer token of the kubeconfig
+	AuthModeUser = "user"
+
+	// DefaultTokenAudience is the audience the service account tokens are requested for by default
+	DefaultTokenAudience = "backyards"
+
+	// the API server does not issue tokens which expire in less than 10 minutes
+	serviceAccountTokenExpiration = 10 * time.Minute
+	tokenRefreshMargin            = time.Minute
+)
+
+// serviceAccountTokenSource caches the token of a service account and requests a new one shortly before it expires,
+// so long running commands keep working with short-lived tokens
+type serviceAccountTokenSource struct {
+	clientset kubernetes.Interface
+	client    k8sclient.Client
+	key       types.NamespacedName
+	audiences []string
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func (s *serviceAccountTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && (s.expiresAt.IsZero() || time.Until(s.expiresAt) > tokenRefreshMargin) {
+		return s.token, nil
+	}
+
+	// the token is bound to the Backyards audience, so it is not accepted by the API server if it is intercepted
+	token, expiresAt, err := k8s.GetTokenForServiceAccount(s.clientset, s.client, s.key, s.audiences, serviceAccountTokenExpiration)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = token
+	s.expiresAt = expiresAt
+
+	return s.token, nil
+}
+
+// tokenRoundTripper sets the token of the service account as the bearer token of every request
+type tokenRoundTripper struct {
+	source *serviceAccountTokenSource
+	next   http.RoundTripper
+}
+
+func (rt *tokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := rt.source.Token()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not get token for the Backyards API")
+	}
+
+	// a round tripper must not modify the original request
+	r := req.WithContext(req.Context())
+	r.Header = req.Header.Clone()
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	return rt.next.RoundTrip(r)
+}
+
+// bearerOnlyRoundTripper makes sure that only bearer tokens of the kubeconfig are sent to the Backyards API,
+// other credentials like basic auth are not forwarded
+type bearerOnlyRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (rt *bearerOnlyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasPrefix(req.Header.Get("Authorization"), "Bearer ") {
+		return nil, errors.New("the kubeconfig has no bearer token to authenticate to the Backyards API as the user, use a token or OIDC based kubeconfig, or --auth-mode=" + AuthModeServiceAccount)
+	}
+
+	return rt.next.RoundTrip(req)
+}
+
+// newAuthenticatedHTTPClient returns a client which authenticates the requests of the given client according to the auth mode,
+// the tokens of the service account are requested for the given audience
+func newAuthenticatedHTTPClient(httpClient *http.Client, mode string, config *rest.Config, client k8sclient.Client, serviceAccount types.NamespacedName, audience string) (*http.Client, error) {
+	next := httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	var transport http.RoundTripper
+	switch mode {
+	case AuthModeServiceAccount, "":
+		clientset, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return nil, errors.WrapIf(err, "could not get k8s clientset")
+		}
+
+		source := &serviceAccountTokenSource{
+			clientset: clientset,
+			client:    client,
+			key:       serviceAccount,
+			audiences: []string{audience},
+		}
+
+		// get the first token right away to fail early
+		_, err = source.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		transport = &tokenRoundTripper{
+			source: source,
+			next:   next,
+		}
+	case AuthModeUser:
+		// the wrappers of the kubeconfig set the bearer token of the user, refresh OIDC tokens and set impersonation headers
+		var err error
+		transport, err = rest.HTTPWrappersForConfig(config, &bearerOnlyRoundTripper{
+			next: next,
+		})
+		if err != nil {
+			return nil, errors.WrapIf(err, "could not get authentication of the kubeconfig")
+		}
+	default:
+		return nil, errors.NewWithDetails("invalid auth mode", "mode", mode, "valid", []string{AuthModeServiceAccount, AuthModeUser})
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   httpClient.Timeout,
+	}, nil
+}
@@ -15,17 +15,23 @@
 package cli
 
 import (
+	"context"
 	"io"
 	"os"
+	"os/signal"
+	"sync"
 
 	"emperror.dev/errors"
 	"github.com/mattn/go-isatty"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 	v1alpha3 "knative.dev/pkg/apis/istio/v1alpha3"
 
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
 	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
 	"github.com/banzaicloud/backyards-cli/pkg/k8s/portforward"
 	"github.com/banzaicloud/backyards-cli/pkg/output"
@@ -33,11 +39,8 @@ import (
 )
 
 var (
-	IGWPort        = 80
-	IGWMatchLabels = map[string]string{
-		"app.kubernetes.io/component": "ingressgateway",
-		"app.kubernetes.io/instance":  "backyards",
-	}
+	IGWServiceName              = "backyards-ingressgateway"
+	IGWPort                     = 80
 	BackyardsServiceAccountName = "backyards"
 )
 
@@ -46,17 +49,24 @@ type CLI interface {
 	OutputFormat() string
 	Color() bool
 	Interactive() bool
+	Context() context.Context
 	InteractiveTerminal() bool
 	GetRootCommand() *cobra.Command
 	GetK8sClient() (k8sclient.Client, error)
 	GetK8sConfig() (*rest.Config, error)
 	GetPortforwardForPod(podLabels map[string]string, namespace string, localPort, remotePort int) (*portforward.Portforward, error)
+	GetPortforwardForService(service types.NamespacedName, podName string, localPort, servicePort int) (*portforward.Portforward, error)
 	GetPortforwardForIGW(localPort int) (*portforward.Portforward, error)
+	GetEndpoint(localPort int) (Endpoint, error)
+	GetGraphQLClient() (graphql.Client, error)
 }
 
 type backyardsCLI struct {
 	out     io.Writer
 	rootCmd *cobra.Command
+
+	ctx     context.Context
+	ctxOnce sync.Once
 }
 
 func NewCli(out io.Writer, rootCmd *cobra.Command) CLI {
@@ -82,6 +92,26 @@ func (c *backyardsCLI) Interactive() bool {
 	return viper.GetBool("formatting.force-interactive")
 }
 
+// Context returns a context which is cancelled when the command is interrupted, so pending requests can be aborted.
+// A second interrupt terminates the process as usual.
+func (c *backyardsCLI) Context() context.Context {
+	c.ctxOnce.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.ctx = ctx
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt)
+		go func() {
+			<-signals
+			signal.Stop(signals)
+			log.Warn("interrupted, cancelling pending requests")
+			cancel()
+		}()
+	})
+
+	return c.ctx
+}
+
 func (c *backyardsCLI) Color() bool {
 	if isatty.IsTerminal(os.Stdout.Fd()) {
 		return !viper.GetBool("formatting.no-color")
@@ -98,8 +128,32 @@ func (c *backyardsCLI) Out() io.Writer {
 	return c.out
 }
 
+// GetPortforwardForIGW returns a port forward to the ingress gateway, to the pinned pod if one is set
 func (c *backyardsCLI) GetPortforwardForIGW(localPort int) (*portforward.Portforward, error) {
-	return c.GetPortforwardForPod(IGWMatchLabels, viper.GetString("backyards.namespace"), localPort, IGWPort)
+	return c.GetPortforwardForService(types.NamespacedName{
+		Name:      IGWServiceName,
+		Namespace: viper.GetString("backyards.namespace"),
+	}, viper.GetString("backyards.pod"), localPort, IGWPort)
+}
+
+// GetPortforwardForService returns a port forward to a ready pod behind the service, or to the given pod if podName is set
+func (c *backyardsCLI) GetPortforwardForService(service types.NamespacedName, podName string, localPort, servicePort int) (*portforward.Portforward, error) {
+	config, err := c.GetK8sConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := c.GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+
+	pf, err := portforward.NewForService(client, config, service, podName, localPort, servicePort)
+	if err != nil {
+		return nil, err
+	}
+
+	return pf, nil
 }
 
 func (c *backyardsCLI) GetPortforwardForPod(podLabels map[string]string, namespace string, localPort, remotePort int) (*portforward.Portforward, error) {
@@ -121,6 +175,60 @@ func (c *backyardsCLI) GetPortforwardForPod(podLabels map[string]string, namespa
 	return pf, nil
 }
 
+// GetEndpoint returns the endpoint of the Backyards API, which is the exposed ingress if its URL is set,
+// otherwise a port forward to the ingress gateway is started on the given local port
+func (c *backyardsCLI) GetEndpoint(localPort int) (Endpoint, error) {
+	if backyardsURL := viper.GetString("backyards.url"); backyardsURL != "" {
+		return newIngressEndpoint(backyardsURL, viper.GetString("backyards.tls.ca-file"), viper.GetBool("backyards.tls.insecure-skip-verify"))
+	}
+
+	pf, err := c.GetPortforwardForIGW(localPort)
+	if err != nil {
+		return nil, err
+	}
+
+	err = pf.RunSupervised()
+	if err != nil {
+		return nil, err
+	}
+
+	return &portforwardEndpoint{
+		pf: pf,
+	}, nil
+}
+
+// GetGraphQLClient returns a client for the Backyards API, which authenticates according to the auth mode
+func (c *backyardsCLI) GetGraphQLClient() (graphql.Client, error) {
+	config, err := c.GetK8sConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	k8sclient, err := c.GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := c.GetEndpoint(0)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := newAuthenticatedHTTPClient(endpoint.HTTPClient(), viper.GetString("backyards.auth.mode"), config, k8sclient, types.NamespacedName{
+		Name:      BackyardsServiceAccountName,
+		Namespace: viper.GetString("backyards.namespace"),
+	}, viper.GetString("backyards.auth.audience"))
+	if err != nil {
+		endpoint.Close()
+		return nil, err
+	}
+
+	client := graphql.NewClient(endpoint.URLForPath("/api/graphql"), graphql.WithHTTPClient(httpClient))
+	client.SetRequestTimeout(viper.GetDuration("request-timeout"))
+
+	return client, nil
+}
+
 func (c *backyardsCLI) GetK8sClient() (k8sclient.Client, error) {
 	config, err := k8sclient.GetConfigWithContext(viper.GetString("kubeconfig"), viper.GetString("kubecontext"))
 	if err != nil {
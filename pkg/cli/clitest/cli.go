@@ -0,0 +1,136 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clitest provides a cli.CLI implementation for tests, which works with an in-memory Kubernetes client
+// and a given GraphQL client instead of a live cluster.
+package clitest
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"emperror.dev/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+	"github.com/banzaicloud/backyards-cli/pkg/k8s/portforward"
+	"github.com/banzaicloud/backyards-cli/pkg/output"
+)
+
+func init() {
+	// the fake client decodes lists with the client-go scheme, so the istio types must be registered there as well
+	_ = v1alpha3.AddToScheme(clientgoscheme.Scheme)
+	_ = v1alpha3.AddToScheme(k8sclient.GetScheme())
+}
+
+// NewK8sClient returns an in-memory Kubernetes client which holds the given objects
+func NewK8sClient(objects ...runtime.Object) k8sclient.Client {
+	return fake.NewFakeClientWithScheme(k8sclient.GetScheme(), objects...)
+}
+
+type FakeCLI struct {
+	out           *bytes.Buffer
+	outputFormat  string
+	k8sClient     k8sclient.Client
+	graphqlClient graphql.Client
+}
+
+var _ cli.CLI = &FakeCLI{}
+
+func NewFakeCLI(k8sClient k8sclient.Client, graphqlClient graphql.Client) *FakeCLI {
+	return &FakeCLI{
+		out:           new(bytes.Buffer),
+		outputFormat:  output.OutputFormatTable,
+		k8sClient:     k8sClient,
+		graphqlClient: graphqlClient,
+	}
+}
+
+// Output returns everything written to the output of the CLI
+func (c *FakeCLI) Output() string {
+	return c.out.String()
+}
+
+func (c *FakeCLI) SetOutputFormat(format string) {
+	c.outputFormat = format
+}
+
+func (c *FakeCLI) Out() io.Writer {
+	return c.out
+}
+
+func (c *FakeCLI) OutputFormat() string {
+	return c.outputFormat
+}
+
+func (c *FakeCLI) Color() bool {
+	return false
+}
+
+func (c *FakeCLI) Interactive() bool {
+	return false
+}
+
+func (c *FakeCLI) InteractiveTerminal() bool {
+	return false
+}
+
+func (c *FakeCLI) Context() context.Context {
+	return context.Background()
+}
+
+func (c *FakeCLI) GetRootCommand() *cobra.Command {
+	return &cobra.Command{}
+}
+
+func (c *FakeCLI) GetK8sClient() (k8sclient.Client, error) {
+	return c.k8sClient, nil
+}
+
+func (c *FakeCLI) GetK8sConfig() (*rest.Config, error) {
+	return &rest.Config{}, nil
+}
+
+func (c *FakeCLI) GetPortforwardForPod(podLabels map[string]string, namespace string, localPort, remotePort int) (*portforward.Portforward, error) {
+	return nil, errors.New("port forwarding is not supported in tests")
+}
+
+func (c *FakeCLI) GetPortforwardForService(service types.NamespacedName, podName string, localPort, servicePort int) (*portforward.Portforward, error) {
+	return nil, errors.New("port forwarding is not supported in tests")
+}
+
+func (c *FakeCLI) GetPortforwardForIGW(localPort int) (*portforward.Portforward, error) {
+	return nil, errors.New("port forwarding is not supported in tests")
+}
+
+func (c *FakeCLI) GetEndpoint(localPort int) (cli.Endpoint, error) {
+	return nil, errors.New("endpoints are not supported in tests")
+}
+
+func (c *FakeCLI) GetGraphQLClient() (graphql.Client, error) {
+	if c.graphqlClient == nil {
+		return nil, errors.New("no graphql client is set")
+	}
+
+	return c.graphqlClient, nil
+}
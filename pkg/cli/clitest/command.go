@@ -0,0 +1,109 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clitest
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/banzaicloud/backyards-cli/pkg/cli"
+	"github.com/banzaicloud/backyards-cli/pkg/graphql/graphqltest"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+)
+
+// MutationTests runs a command with the arguments of each test case against a fake CLI and checks the mutation it sends
+type MutationTests struct {
+	// Field is the field of the mutation sent by the command, e.g. applyHTTPRoute
+	Field string
+	// NewCommand returns the command under test
+	NewCommand func(cli cli.CLI) *cobra.Command
+	// Objects are the Kubernetes objects of the test cases which do not have their own
+	Objects []runtime.Object
+	Cases   []MutationTestCase
+}
+
+// MutationTestCase is a test case of MutationTests, no mutation is expected if neither WantInput nor Check is set
+type MutationTestCase struct {
+	Name    string
+	Args    []string
+	Objects []runtime.Object
+	// Respond scripts the response of the GraphQL API, the mutation is confirmed by default
+	Respond func(s *graphqltest.Server)
+	WantErr bool
+	// WantInput is a pointer to the expected input of the mutation
+	WantInput interface{}
+	// Check checks the mutation and the objects after the command is executed
+	Check func(t *testing.T, mutation graphqltest.Request, k8sClient k8sclient.Client)
+}
+
+func (m MutationTests) Run(t *testing.T) {
+	for _, test := range m.Cases {
+		test := test
+		t.Run(test.Name, func(t *testing.T) {
+			server := graphqltest.NewServer()
+			defer server.Close()
+			if test.Respond != nil {
+				test.Respond(server)
+			} else {
+				server.Respond(m.Field, true)
+			}
+
+			objects := test.Objects
+			if objects == nil {
+				objects = m.Objects
+			}
+			k8sClient := NewK8sClient(objects...)
+
+			cmd := m.NewCommand(NewFakeCLI(k8sClient, server.Client()))
+			cmd.SetArgs(test.Args)
+			cmd.SetOutput(ioutil.Discard)
+
+			err := cmd.Execute()
+			if (err != nil) != test.WantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			mutations := server.Mutations()
+			if test.WantInput == nil && test.Check == nil {
+				if len(mutations) > 0 {
+					t.Fatalf("unexpected mutations: %+v", mutations)
+				}
+				return
+			}
+
+			if len(mutations) != 1 || mutations[0].Field != m.Field {
+				t.Fatalf("expected a single %s mutation, got %+v", m.Field, mutations)
+			}
+
+			if test.WantInput != nil {
+				input := reflect.New(reflect.TypeOf(test.WantInput).Elem()).Interface()
+				if err := mutations[0].DecodeVariable("input", input); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(input, test.WantInput) {
+					t.Errorf("unexpected input\ngot : %+v\nwant: %+v", reflect.ValueOf(input).Elem(), reflect.ValueOf(test.WantInput).Elem())
+				}
+			}
+
+			if test.Check != nil {
+				test.Check(t, mutations[0], k8sClient)
+			}
+		})
+	}
+}
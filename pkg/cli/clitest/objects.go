@@ -0,0 +1,73 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clitest
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis/istio/v1alpha3"
+)
+
+const (
+	DemoNamespace = "backyards-demo"
+	DemoService   = "movies"
+)
+
+// NewDemoService returns the movies service of the demo application, which selects the pods with the app=movies label
+func NewDemoService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: DemoService, Namespace: DemoNamespace},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": DemoService}},
+	}
+}
+
+// NewDemoDestinationRule returns the destination rule of the movies service with a subset for each version
+func NewDemoDestinationRule(versions ...string) *v1alpha3.DestinationRule {
+	drule := &v1alpha3.DestinationRule{
+		ObjectMeta: metav1.ObjectMeta{Name: DemoService, Namespace: DemoNamespace},
+		Spec: v1alpha3.DestinationRuleSpec{
+			Host: DemoService,
+		},
+	}
+	for _, version := range versions {
+		drule.Spec.Subsets = append(drule.Spec.Subsets, v1alpha3.Subset{
+			Name:   version,
+			Labels: map[string]string{"version": version},
+		})
+	}
+
+	return drule
+}
+
+// NewDemoPods returns a ready pod of the movies service for each version
+func NewDemoPods(versions ...string) []runtime.Object {
+	pods := make([]runtime.Object, 0, len(versions))
+	for _, version := range versions {
+		pods = append(pods, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      DemoService + "-" + version,
+				Namespace: DemoNamespace,
+				Labels:    map[string]string{"app": DemoService, "version": version},
+			},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		})
+	}
+
+	return pods
+}
@@ -17,7 +17,9 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"time"
 
 	"emperror.dev/errors"
 	logrushandler "emperror.dev/handler/logrus"
@@ -26,20 +28,26 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/analyze"
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/canary"
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/certmanager"
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/demoapp"
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/graph"
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/istio"
 	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/routing"
+	"github.com/banzaicloud/backyards-cli/internal/cli/cmd/services"
 	"github.com/banzaicloud/backyards-cli/pkg/cli"
 )
 
 const (
-	defaultNamespace = "backyards-system"
+	defaultNamespace      = "backyards-system"
+	defaultRequestTimeout = 30 * time.Second
+	defaultConfigDir      = ".backyards"
+	defaultConfigName     = "config"
 )
 
 var (
+	configFile         string
 	backyardsNamespace string
 	kubeconfigPath     string
 	kubeContext        string
@@ -63,6 +71,11 @@ var RootCmd = &cobra.Command{
 			log.SetLevel(log.InfoLevel)
 		}
 
+		err := initConfig()
+		if err != nil {
+			return err
+		}
+
 		namespaceFromEnv := os.Getenv("BACKYARDS_NAMESPACE")
 		if backyardsNamespace == defaultNamespace && namespaceFromEnv != "" {
 			backyardsNamespace = namespaceFromEnv
@@ -76,6 +89,34 @@ var RootCmd = &cobra.Command{
 	},
 }
 
+// initConfig reads the config file if there is one, its keys are the same as the ones flags are bound to, e.g.
+//
+//	backyards:
+//	  url: https://backyards.example.com
+func initConfig() error {
+	if configFile != "" {
+		viper.SetConfigFile(configFile)
+	} else {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return errors.WrapIf(err, "could not get home directory")
+		}
+		viper.AddConfigPath(filepath.Join(home, defaultConfigDir))
+		viper.SetConfigName(defaultConfigName)
+	}
+
+	err := viper.ReadInConfig()
+	if err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); ok && configFile == "" {
+			return nil
+		}
+		return errors.WrapIf(err, "could not read config file")
+	}
+	log.Debugf("using config file %s", viper.ConfigFileUsed())
+
+	return nil
+}
+
 // Init is a temporary function to set initial values in the root cmd
 func Init(version string, commitHash string, buildDate string) {
 	RootCmd.Version = version
@@ -105,6 +146,7 @@ func Execute() {
 
 func init() {
 	flags := RootCmd.PersistentFlags()
+	flags.StringVar(&configFile, "config", "", "path to the config file (default $HOME/"+defaultConfigDir+"/"+defaultConfigName+".yaml)")
 	flags.StringVarP(&backyardsNamespace, "namespace", "n", defaultNamespace, "namespace in which Backyards is installed [$BACKYARDS_NAMESPACE]")
 	_ = viper.BindPFlag("backyards.namespace", flags.Lookup("namespace"))
 	flags.StringVarP(&kubeconfigPath, "kubeconfig", "c", "", "path to the kubeconfig file to use for CLI requests")
@@ -112,6 +154,25 @@ func init() {
 	flags.StringVar(&kubeContext, "context", "", "name of the kubeconfig context to use")
 	_ = viper.BindPFlag("kubecontext", flags.Lookup("context"))
 	flags.BoolVarP(&verbose, "verbose", "v", false, "turn on debug logging")
+	flags.Duration("request-timeout", defaultRequestTimeout, "timeout of requests to the Backyards API, 0 means no timeout")
+	_ = viper.BindPFlag("request-timeout", flags.Lookup("request-timeout"))
+	flags.String("backyards-url", "", "URL of the exposed Backyards ingress, port forwarding to the ingress gateway is used if not set [$BACKYARDS_URL]")
+	_ = viper.BindPFlag("backyards.url", flags.Lookup("backyards-url"))
+	_ = viper.BindEnv("backyards.url", "BACKYARDS_URL")
+	flags.String("backyards-ca-file", "", "path to the CA bundle to verify the certificate of the Backyards ingress with [$BACKYARDS_CA_FILE]")
+	_ = viper.BindPFlag("backyards.tls.ca-file", flags.Lookup("backyards-ca-file"))
+	_ = viper.BindEnv("backyards.tls.ca-file", "BACKYARDS_CA_FILE")
+	flags.Bool("backyards-insecure-skip-verify", false, "do not verify the certificate of the Backyards ingress [$BACKYARDS_INSECURE_SKIP_VERIFY]")
+	_ = viper.BindPFlag("backyards.tls.insecure-skip-verify", flags.Lookup("backyards-insecure-skip-verify"))
+	_ = viper.BindEnv("backyards.tls.insecure-skip-verify", "BACKYARDS_INSECURE_SKIP_VERIFY")
+	flags.String("auth-mode", cli.AuthModeServiceAccount, "how to authenticate to the Backyards API, either as the backyards service account ("+cli.AuthModeServiceAccount+") or as the user of the kubeconfig ("+cli.AuthModeUser+") [$BACKYARDS_AUTH_MODE]")
+	_ = viper.BindPFlag("backyards.auth.mode", flags.Lookup("auth-mode"))
+	_ = viper.BindEnv("backyards.auth.mode", "BACKYARDS_AUTH_MODE")
+	flags.String("auth-audience", cli.DefaultTokenAudience, "audience of the service account tokens, the Backyards API must verify the tokens for the same audience [$BACKYARDS_AUTH_AUDIENCE]")
+	_ = viper.BindPFlag("backyards.auth.audience", flags.Lookup("auth-audience"))
+	_ = viper.BindEnv("backyards.auth.audience", "BACKYARDS_AUTH_AUDIENCE")
+	flags.String("pod", "", "name of the ingress gateway pod to port forward to, a ready pod is selected if not set")
+	_ = viper.BindPFlag("backyards.pod", flags.Lookup("pod"))
 
 	flags.StringVarP(&outputFormat, "output", "o", "table", "output format (table|yaml|json)")
 	_ = viper.BindPFlag("output.format", flags.Lookup("output"))
@@ -127,6 +188,9 @@ func init() {
 	RootCmd.AddCommand(cmd.NewVersionCommand(cli))
 	RootCmd.AddCommand(cmd.NewInstallCommand(cli))
 	RootCmd.AddCommand(cmd.NewUninstallCommand(cli))
+	RootCmd.AddCommand(cmd.NewUpgradeCommand(cli))
+	RootCmd.AddCommand(cmd.NewHistoryCommand(cli))
+	RootCmd.AddCommand(cmd.NewRollbackCommand(cli))
 	RootCmd.AddCommand(cmd.NewDashboardCommand(cli, cmd.NewDashboardOptions()))
 	RootCmd.AddCommand(istio.NewRootCmd(cli))
 	RootCmd.AddCommand(canary.NewRootCmd(cli))
@@ -134,4 +198,6 @@ func init() {
 	RootCmd.AddCommand(routing.NewRootCmd(cli))
 	RootCmd.AddCommand(certmanager.NewRootCmd(cli))
 	RootCmd.AddCommand(graph.NewGraphCmd(cli, "base.json"))
+	RootCmd.AddCommand(analyze.NewAnalyzeCommand(cli))
+	RootCmd.AddCommand(services.NewRootCmd(cli))
 }
@@ -0,0 +1,113 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/backyards-cli/pkg/k8s/portforward"
+)
+
+// Endpoint is where the Backyards API can be reached, either through a port forward to the ingress gateway
+// or directly through an exposed ingress
+type Endpoint interface {
+	URLForPath(path string) string
+	HTTPClient() *http.Client
+	// Wait blocks until a port forwarded endpoint is closed, it returns immediately for a direct endpoint
+	Wait()
+	Close()
+}
+
+type portforwardEndpoint struct {
+	pf *portforward.Portforward
+}
+
+func (e *portforwardEndpoint) URLForPath(path string) string {
+	return e.pf.GetURL(path)
+}
+
+func (e *portforwardEndpoint) HTTPClient() *http.Client {
+	return http.DefaultClient
+}
+
+func (e *portforwardEndpoint) Wait() {
+	e.pf.WaitForStop()
+}
+
+func (e *portforwardEndpoint) Close() {
+	e.pf.Stop()
+}
+
+type ingressEndpoint struct {
+	url    *url.URL
+	client *http.Client
+}
+
+// newIngressEndpoint returns an endpoint for the Backyards ingress on the given URL, the certificate of the
+// ingress is verified with the CAs in caFile if it is set, or with the system CAs otherwise
+func newIngressEndpoint(rawURL, caFile string, insecureSkipVerify bool) (*ingressEndpoint, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "invalid Backyards URL", "url", rawURL)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.NewWithDetails("Backyards URL must start with http:// or https://", "url", rawURL)
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: insecureSkipVerify, // nolint: gosec
+	}
+
+	if caFile != "" {
+		data, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not read CA file", "path", caFile)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(data) {
+			return nil, errors.NewWithDetails("no certificates found in CA file", "path", caFile)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &ingressEndpoint{
+		url: u,
+		client: &http.Client{
+			Transport: transport,
+		},
+	}, nil
+}
+
+func (e *ingressEndpoint) URLForPath(path string) string {
+	return strings.TrimSuffix(e.url.String(), "/") + path
+}
+
+func (e *ingressEndpoint) HTTPClient() *http.Client {
+	return e.client
+}
+
+func (e *ingressEndpoint) Wait() {}
+
+func (e *ingressEndpoint) Close() {}
@@ -0,0 +1,106 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestIngressEndpoint(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/graphql" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	caFile, err := ioutil.TempFile("", "backyards-ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(caFile.Name())
+	err = pem.Encode(caFile, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile.Close()
+
+	tests := []struct {
+		name               string
+		url                string
+		caFile             string
+		insecureSkipVerify bool
+		wantErr            bool
+		wantRequestErr     bool
+	}{
+		{
+			name:   "verifies the certificate with the given CA",
+			url:    server.URL + "/",
+			caFile: caFile.Name(),
+		},
+		{
+			name:               "skips verification if insecure",
+			url:                server.URL,
+			insecureSkipVerify: true,
+		},
+		{
+			name:           "rejects unknown certificates",
+			url:            server.URL,
+			wantRequestErr: true,
+		},
+		{
+			name:    "rejects URLs without a scheme",
+			url:     "backyards.example.com",
+			wantErr: true,
+		},
+		{
+			name:    "fails on a missing CA file",
+			url:     server.URL,
+			caFile:  caFile.Name() + "-missing",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			endpoint, err := newIngressEndpoint(test.url, test.caFile, test.insecureSkipVerify)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+
+			resp, err := endpoint.HTTPClient().Get(endpoint.URLForPath("/api/graphql"))
+			if (err != nil) != test.wantRequestErr {
+				t.Fatalf("unexpected request error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("unexpected status code: %d", resp.StatusCode)
+			}
+		})
+	}
+}
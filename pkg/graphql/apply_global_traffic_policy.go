@@ -27,11 +27,12 @@ type ApplyGlobalTrafficPolicyRequest struct {
 	Namespace        string                           `json:"namespace"`
 	ConnectionPool   *v1alpha3.ConnectionPoolSettings `json:"connectionPoolSettings,omitempty"`
 	OutlierDetection *v1alpha3.OutlierDetection       `json:"outlierDetection,omitempty"`
+	LoadBalancer     *v1alpha3.LoadBalancerSettings   `json:"loadBalancer,omitempty"`
 }
 
 type ApplyGlobalTrafficPolicyResponse bool
 
-func (c *client) ApplyGlobalTrafficPolicy(req ApplyGlobalTrafficPolicyRequest) (ApplyGlobalTrafficPolicyResponse, error) {
+func (c *client) ApplyGlobalTrafficPolicy(ctx context.Context, req ApplyGlobalTrafficPolicyRequest) (ApplyGlobalTrafficPolicyResponse, error) {
 	request := heredoc.Doc(`
 	  mutation applyGlobalTrafficPolicy(
 		$input: ApplyGlobalTrafficPolicyInput!
@@ -47,7 +48,7 @@ func (c *client) ApplyGlobalTrafficPolicy(req ApplyGlobalTrafficPolicyRequest) (
 
 	// run it and capture the response
 	var respData map[string]ApplyGlobalTrafficPolicyResponse
-	if err := c.client.Run(context.Background(), r, &respData); err != nil {
+	if err := c.mutate(ctx, r, &respData); err != nil {
 		return false, err
 	}
 
@@ -18,6 +18,7 @@ import (
 	"context"
 
 	"github.com/MakeNowJust/heredoc"
+	"knative.dev/pkg/apis/istio/v1alpha3"
 )
 
 type HTTPRouteDestination struct {
@@ -36,15 +37,30 @@ type PortSelector struct {
 	Name   string `json:"name,omitempty"`
 }
 
+type HTTPRetry struct {
+	Attempts      int    `json:"attempts"`
+	PerTryTimeout string `json:"perTryTimeout,omitempty"`
+	RetryOn       string `json:"retryOn,omitempty"`
+}
+
 type ApplyHTTPRouteRequest struct {
-	Name      string                 `json:"name"`
-	Namespace string                 `json:"namespace"`
-	Route     []HTTPRouteDestination `json:"route,omitempty"`
+	Name      string                      `json:"name"`
+	Namespace string                      `json:"namespace"`
+	Match     []v1alpha3.HTTPMatchRequest `json:"match,omitempty"`
+	Route     []HTTPRouteDestination      `json:"route,omitempty"`
+
+	Mirror        *Destination `json:"mirror,omitempty"`
+	MirrorPercent *int         `json:"mirrorPercent,omitempty"`
+
+	Fault *v1alpha3.HTTPFaultInjection `json:"fault,omitempty"`
+
+	Timeout string     `json:"timeout,omitempty"`
+	Retries *HTTPRetry `json:"retries,omitempty"`
 }
 
 type ApplyHTTPRouteResponse bool
 
-func (c *client) ApplyHTTPRoute(req ApplyHTTPRouteRequest) (ApplyHTTPRouteResponse, error) {
+func (c *client) ApplyHTTPRoute(ctx context.Context, req ApplyHTTPRouteRequest) (ApplyHTTPRouteResponse, error) {
 	request := heredoc.Doc(`
 	  mutation applyHTTPRoute(
 		$input: ApplyHTTPRouteInput!
@@ -60,7 +76,7 @@ func (c *client) ApplyHTTPRoute(req ApplyHTTPRouteRequest) (ApplyHTTPRouteRespon
 
 	// run it and capture the response
 	var respData map[string]ApplyHTTPRouteResponse
-	if err := c.client.Run(context.Background(), r, &respData); err != nil {
+	if err := c.mutate(ctx, r, &respData); err != nil {
 		return false, err
 	}
 
@@ -15,35 +15,101 @@
 package graphql
 
 import (
-	"github.com/machinebox/graphql"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"emperror.dev/errors"
+)
+
+const (
+	defaultRetries      = 3
+	defaultRetryBackoff = 500 * time.Millisecond
 )
 
 type Client interface {
 	SetJWTToken(string)
-	GenerateLoad(req GenerateLoadRequest) (GenerateLoadResponse, error)
-	ApplyHTTPRoute(req ApplyHTTPRouteRequest) (ApplyHTTPRouteResponse, error)
-	DisableHTTPRoute(req DisableHTTPRouteRequest) (DisableHTTPRouteResponse, error)
-	ApplyGlobalTrafficPolicy(req ApplyGlobalTrafficPolicyRequest) (ApplyGlobalTrafficPolicyResponse, error)
-	DisableGlobalTrafficPolicy(req DisableGlobalTrafficPolicyRequest) (DisableGlobalTrafficPolicyResponse, error)
+	SetRequestTimeout(time.Duration)
+	GenerateLoad(ctx context.Context, req GenerateLoadRequest) (GenerateLoadResponse, error)
+	ApplyHTTPRoute(ctx context.Context, req ApplyHTTPRouteRequest) (ApplyHTTPRouteResponse, error)
+	DisableHTTPRoute(ctx context.Context, req DisableHTTPRouteRequest) (DisableHTTPRouteResponse, error)
+	ApplyGlobalTrafficPolicy(ctx context.Context, req ApplyGlobalTrafficPolicyRequest) (ApplyGlobalTrafficPolicyResponse, error)
+	DisableGlobalTrafficPolicy(ctx context.Context, req DisableGlobalTrafficPolicyRequest) (DisableGlobalTrafficPolicyResponse, error)
+	Services(ctx context.Context, req ServicesRequest) (ServicesResponse, error)
+	Service(ctx context.Context, req ServiceRequest) (*MeshService, error)
+	Workloads(ctx context.Context, req WorkloadsRequest) (WorkloadsResponse, error)
 }
 
 type client struct {
-	jwtToken string
-	client   *graphql.Client
+	endpoint       string
+	httpClient     *http.Client
+	jwtToken       string
+	requestTimeout time.Duration
+	retries        int
+	retryBackoff   time.Duration
+}
+
+type ClientOption func(c *client)
+
+// WithHTTPClient sets the HTTP client used to send the requests
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *client) {
+		c.httpClient = httpClient
+	}
 }
 
-func NewClient(url string, opt ...graphql.ClientOption) Client {
-	return &client{
-		client: graphql.NewClient(url, opt...),
+// WithRetries sets how many times a failed query is retried and the initial delay between the attempts
+func WithRetries(retries int, backoff time.Duration) ClientOption {
+	return func(c *client) {
+		c.retries = retries
+		c.retryBackoff = backoff
 	}
 }
 
+func NewClient(url string, opts ...ClientOption) Client {
+	c := &client{
+		endpoint:     url,
+		httpClient:   http.DefaultClient,
+		retries:      defaultRetries,
+		retryBackoff: defaultRetryBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
 func (c *client) SetJWTToken(token string) {
 	c.jwtToken = token
 }
 
-func (c *client) NewRequest(q string) *graphql.Request {
-	r := graphql.NewRequest(q)
+// SetRequestTimeout limits the time a single call may take including its retries, zero means no limit
+func (c *client) SetRequestTimeout(timeout time.Duration) {
+	c.requestTimeout = timeout
+}
+
+type Request struct {
+	query string
+	vars  map[string]interface{}
+
+	Header http.Header
+}
+
+func (r *Request) Var(key string, value interface{}) {
+	r.vars[key] = value
+}
+
+func (c *client) NewRequest(q string) *Request {
+	r := &Request{
+		query:  q,
+		vars:   make(map[string]interface{}),
+		Header: make(http.Header),
+	}
 
 	// set header fields
 	if c.jwtToken != "" {
@@ -53,3 +119,114 @@ func (c *client) NewRequest(q string) *graphql.Request {
 
 	return r
 }
+
+// mutate runs a request which changes state exactly once, since it is not safe to repeat it
+func (c *client) mutate(ctx context.Context, r *Request, resp interface{}) error {
+	ctx, cancel := c.withTimeout(ctx, c.requestTimeout)
+	defer cancel()
+
+	return c.run(ctx, r, resp)
+}
+
+// query runs a read-only request and retries it with exponential backoff on transient failures
+func (c *client) query(ctx context.Context, r *Request, resp interface{}) error {
+	ctx, cancel := c.withTimeout(ctx, c.requestTimeout)
+	defer cancel()
+
+	backoff := c.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := c.run(ctx, r, resp)
+		if err == nil || attempt >= c.retries || !isTransient(err) || ctx.Err() != nil {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return errors.WrapIf(ctx.Err(), "request cancelled while waiting to retry")
+		}
+	}
+}
+
+func (c *client) withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+type requestBody struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+type responseBody struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []graphQLError  `json:"errors"`
+}
+
+type graphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path"`
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+func (c *client) run(ctx context.Context, r *Request, resp interface{}) error {
+	var body bytes.Buffer
+	err := json.NewEncoder(&body).Encode(requestBody{
+		Query:     r.query,
+		Variables: r.vars,
+	})
+	if err != nil {
+		return errors.WrapIf(err, "could not encode graphql request")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, &body)
+	if err != nil {
+		return errors.WrapIf(err, "could not create graphql request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Accept", "application/json; charset=utf-8")
+	for key, values := range r.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return errors.WrapIf(ctx.Err(), "request to the Backyards API timed out, use --request-timeout to raise the limit")
+		}
+		return errors.WrapIf(err, "could not send graphql request")
+	}
+	defer res.Body.Close()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, res.Body); err != nil {
+		return errors.WrapIf(err, "could not read graphql response")
+	}
+
+	var gr responseBody
+	if err := json.Unmarshal(buf.Bytes(), &gr); err != nil {
+		// the GraphQL endpoint replies with JSON even on errors, anything else comes from a proxy in front of it
+		return newHTTPError(res.StatusCode, buf.String())
+	}
+
+	if len(gr.Errors) > 0 {
+		return newError(gr.Errors[0])
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return newHTTPError(res.StatusCode, buf.String())
+	}
+
+	if resp == nil || len(gr.Data) == 0 {
+		return nil
+	}
+
+	return errors.WrapIf(json.Unmarshal(gr.Data, resp), "could not decode graphql response")
+}
@@ -0,0 +1,104 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		check  func(err error) bool
+	}{
+		{
+			name:   "not found",
+			status: http.StatusOK,
+			body:   `{"errors":[{"message":"service not found","extensions":{"code":"NOT_FOUND"}}],"data":null}`,
+			check:  IsNotFound,
+		},
+		{
+			name:   "forbidden",
+			status: http.StatusOK,
+			body:   `{"errors":[{"message":"access denied","extensions":{"code":"FORBIDDEN"}}],"data":null}`,
+			check:  IsForbidden,
+		},
+		{
+			name:   "validation",
+			status: http.StatusUnprocessableEntity,
+			body:   `{"errors":[{"message":"invalid weight","path":["applyHTTPRoute"],"extensions":{"code":"GRAPHQL_VALIDATION_FAILED"}}],"data":null}`,
+			check:  IsValidation,
+		},
+		{
+			name:   "unauthorized",
+			status: http.StatusUnauthorized,
+			body:   "Unauthorized",
+			check:  IsForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				fmt.Fprint(w, test.body)
+			}))
+			defer server.Close()
+
+			_, err := NewClient(server.URL).DisableHTTPRoute(context.Background(), DisableHTTPRouteRequest{})
+			if err == nil || !test.check(err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientQueryRetries(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"value":true}}`)
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, WithRetries(3, time.Millisecond)).(*client)
+
+	var resp map[string]bool
+	err := c.query(context.Background(), c.NewRequest("query { value }"), &resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 || !resp["value"] {
+		t.Errorf("unexpected result after %d calls: %v", calls, resp)
+	}
+
+	calls = 0
+	err = c.mutate(context.Background(), c.NewRequest("mutation { value }"), &resp)
+	if err == nil || calls != 1 {
+		t.Errorf("mutation must not be retried, got %d calls and error %v", calls, err)
+	}
+}
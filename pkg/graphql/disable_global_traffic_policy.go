@@ -28,7 +28,7 @@ type DisableGlobalTrafficPolicyRequest struct {
 
 type DisableGlobalTrafficPolicyResponse bool
 
-func (c *client) DisableGlobalTrafficPolicy(req DisableGlobalTrafficPolicyRequest) (DisableGlobalTrafficPolicyResponse, error) {
+func (c *client) DisableGlobalTrafficPolicy(ctx context.Context, req DisableGlobalTrafficPolicyRequest) (DisableGlobalTrafficPolicyResponse, error) {
 	request := heredoc.Doc(`
 	  mutation disableGlobalTrafficPolicyRequest(
 		$input: DisableGlobalTrafficPolicyInput!
@@ -44,7 +44,7 @@ func (c *client) DisableGlobalTrafficPolicy(req DisableGlobalTrafficPolicyReques
 
 	// run it and capture the response
 	var respData map[string]DisableGlobalTrafficPolicyResponse
-	if err := c.client.Run(context.Background(), r, &respData); err != nil {
+	if err := c.mutate(ctx, r, &respData); err != nil {
 		return false, err
 	}
 
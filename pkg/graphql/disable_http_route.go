@@ -18,17 +18,19 @@ import (
 	"context"
 
 	"github.com/MakeNowJust/heredoc"
+	"knative.dev/pkg/apis/istio/v1alpha3"
 )
 
 type DisableHTTPRouteRequest struct {
-	Name      string   `json:"name"`
-	Namespace string   `json:"namespace"`
-	Rules     []string `json:"rules"`
+	Name      string                      `json:"name"`
+	Namespace string                      `json:"namespace"`
+	Match     []v1alpha3.HTTPMatchRequest `json:"match,omitempty"`
+	Rules     []string                    `json:"rules"`
 }
 
 type DisableHTTPRouteResponse bool
 
-func (c *client) DisableHTTPRoute(req DisableHTTPRouteRequest) (DisableHTTPRouteResponse, error) {
+func (c *client) DisableHTTPRoute(ctx context.Context, req DisableHTTPRouteRequest) (DisableHTTPRouteResponse, error) {
 	request := heredoc.Doc(`
 	  mutation disableHTTPRoute(
 		$input: DisableHTTPRouteInput!
@@ -44,7 +46,7 @@ func (c *client) DisableHTTPRoute(req DisableHTTPRouteRequest) (DisableHTTPRoute
 
 	// run it and capture the response
 	var respData map[string]DisableHTTPRouteResponse
-	if err := c.client.Run(context.Background(), r, &respData); err != nil {
+	if err := c.mutate(ctx, r, &respData); err != nil {
 		return false, err
 	}
 
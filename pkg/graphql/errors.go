@@ -0,0 +1,162 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"emperror.dev/errors"
+)
+
+const maxErrorBodyLength = 200
+
+// Error is an error returned by the GraphQL API which does not fit any of the more specific error types
+type Error struct {
+	Message string
+	Code    string
+	Path    string
+}
+
+func (e Error) Error() string {
+	if e.Path != "" {
+		return fmt.Sprintf("%s: %s", e.Path, e.Message)
+	}
+
+	return e.Message
+}
+
+// NotFoundError is returned when an object referenced by the request does not exist
+type NotFoundError struct {
+	Message string
+}
+
+func (e NotFoundError) Error() string {
+	return e.Message
+}
+
+// ForbiddenError is returned when the caller is not allowed to perform the request
+type ForbiddenError struct {
+	Message string
+}
+
+func (e ForbiddenError) Error() string {
+	return fmt.Sprintf("permission denied: %s, check that the current Kubernetes user has access to Backyards", e.Message)
+}
+
+// ValidationError is returned when the request is rejected because of invalid input
+type ValidationError struct {
+	Message string
+	Path    string
+}
+
+func (e ValidationError) Error() string {
+	if e.Path != "" {
+		return fmt.Sprintf("invalid request: %s: %s", e.Path, e.Message)
+	}
+
+	return fmt.Sprintf("invalid request: %s", e.Message)
+}
+
+// HTTPError is returned when the API responds with something other than a GraphQL response
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e HTTPError) Error() string {
+	msg := fmt.Sprintf("unexpected response from the Backyards API: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+
+	return msg
+}
+
+func IsNotFound(err error) bool {
+	var e NotFoundError
+	return errors.As(err, &e)
+}
+
+func IsForbidden(err error) bool {
+	var e ForbiddenError
+	return errors.As(err, &e)
+}
+
+func IsValidation(err error) bool {
+	var e ValidationError
+	return errors.As(err, &e)
+}
+
+func newError(gerr graphQLError) error {
+	path := make([]string, 0, len(gerr.Path))
+	for _, p := range gerr.Path {
+		path = append(path, fmt.Sprint(p))
+	}
+
+	code, _ := gerr.Extensions["code"].(string)
+	switch strings.ToUpper(code) {
+	case "NOT_FOUND":
+		return errors.WithStack(NotFoundError{
+			Message: gerr.Message,
+		})
+	case "FORBIDDEN", "UNAUTHENTICATED", "UNAUTHORIZED":
+		return errors.WithStack(ForbiddenError{
+			Message: gerr.Message,
+		})
+	case "GRAPHQL_PARSE_FAILED", "GRAPHQL_VALIDATION_FAILED", "BAD_USER_INPUT", "VALIDATION_FAILED":
+		return errors.WithStack(ValidationError{
+			Message: gerr.Message,
+			Path:    strings.Join(path, "."),
+		})
+	}
+
+	return errors.WithStack(Error{
+		Message: gerr.Message,
+		Code:    code,
+		Path:    strings.Join(path, "."),
+	})
+}
+
+func newHTTPError(statusCode int, body string) error {
+	body = strings.TrimSpace(body)
+	if len(body) > maxErrorBodyLength {
+		body = body[:maxErrorBodyLength] + "..."
+	}
+
+	if statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden {
+		return errors.WithStack(ForbiddenError{
+			Message: http.StatusText(statusCode),
+		})
+	}
+
+	return errors.WithStack(HTTPError{
+		StatusCode: statusCode,
+		Body:       body,
+	})
+}
+
+// isTransient tells whether a failed request is worth retrying
+func isTransient(err error) bool {
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError || httpErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
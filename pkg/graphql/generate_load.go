@@ -16,6 +16,7 @@ package graphql
 
 import (
 	"context"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
 )
@@ -33,7 +34,7 @@ type GenerateLoadRequest struct {
 
 type GenerateLoadResponse map[string]int
 
-func (c *client) GenerateLoad(req GenerateLoadRequest) (GenerateLoadResponse, error) {
+func (c *client) GenerateLoad(ctx context.Context, req GenerateLoadRequest) (GenerateLoadResponse, error) {
 	request := heredoc.Doc(`
 	mutation load($namespace: String!, $service: String!, $port: Int!, $endpoint: String!, $method: String!, $body: String, $headers: Map, $frequency: Int!, $duration: Int!) {
 		generateLoad(namespace: $namespace, service: $service, port: $port, endpoint: $endpoint, method: $method, body: $body, headers: $headers, frequency: $frequency, duration: $duration)
@@ -51,9 +52,17 @@ func (c *client) GenerateLoad(req GenerateLoadRequest) (GenerateLoadResponse, er
 	r.Var("duration", req.Duration)
 	r.Var("headers", req.Headers)
 
+	// the call only returns when the load generation is finished
+	timeout := c.requestTimeout
+	if timeout > 0 {
+		timeout += time.Duration(req.Duration) * time.Second
+	}
+	ctx, cancel := c.withTimeout(ctx, timeout)
+	defer cancel()
+
 	// run it and capture the response
 	var respData map[string]GenerateLoadResponse
-	if err := c.client.Run(context.Background(), r, &respData); err != nil {
+	if err := c.run(ctx, r, &respData); err != nil {
 		return nil, err
 	}
 
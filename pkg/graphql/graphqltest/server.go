@@ -0,0 +1,188 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package graphqltest provides an in-process stand-in for the Backyards GraphQL API, which records the received
+// requests and replies with scripted responses.
+package graphqltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/backyards-cli/pkg/graphql"
+)
+
+const (
+	OperationQuery    = "query"
+	OperationMutation = "mutation"
+)
+
+// matches the operation type and the name of the first field of the selection set
+var operationRegexp = regexp.MustCompile(`^\s*(query|mutation)?\s*\w*\s*(?:\([^)]*\))?\s*\{\s*(\w+)`)
+
+// Request is a GraphQL request received by the server
+type Request struct {
+	Type      string
+	Field     string
+	Query     string
+	Variables map[string]interface{}
+}
+
+// DecodeVariable decodes a variable of the request into a typed value, e.g. the input of a mutation
+func (r Request) DecodeVariable(name string, value interface{}) error {
+	raw, err := json.Marshal(r.Variables[name])
+	if err != nil {
+		return errors.WrapIf(err, "could not encode variable")
+	}
+
+	return errors.WrapIf(json.Unmarshal(raw, value), "could not decode variable")
+}
+
+type response struct {
+	data   interface{}
+	errors []map[string]interface{}
+}
+
+// Server replies to the requests with the responses scripted for the field of the operation
+type Server struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	responses map[string]response
+	requests  []Request
+}
+
+func NewServer() *Server {
+	s := &Server{
+		responses: make(map[string]response),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Client returns a client connected to the server, which does not retry failed queries
+func (s *Server) Client() graphql.Client {
+	return graphql.NewClient(s.URL(), graphql.WithRetries(0, 0))
+}
+
+// Respond sets the value returned for the given field, e.g. applyHTTPRoute
+func (s *Server) Respond(field string, data interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[field] = response{
+		data: data,
+	}
+}
+
+// RespondError makes the requests for the given field fail with an error, the code is returned as the code extension
+func (s *Server) RespondError(field, code, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[field] = response{
+		errors: []map[string]interface{}{
+			{
+				"message":    message,
+				"path":       []string{field},
+				"extensions": map[string]interface{}{"code": code},
+			},
+		},
+	}
+}
+
+// Requests returns every request received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// Mutations returns the mutations received so far
+func (s *Server) Mutations() []Request {
+	mutations := make([]Request, 0)
+	for _, r := range s.Requests() {
+		if r.Type == OperationMutation {
+			mutations = append(mutations, r)
+		}
+	}
+
+	return mutations
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	match := operationRegexp.FindStringSubmatch(body.Query)
+	if match == nil {
+		http.Error(w, "could not parse operation", http.StatusBadRequest)
+		return
+	}
+
+	request := Request{
+		Type:      match[1],
+		Field:     match[2],
+		Query:     body.Query,
+		Variables: body.Variables,
+	}
+	if request.Type == "" {
+		request.Type = OperationQuery
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, request)
+	resp, ok := s.responses[request.Field]
+	s.mu.Unlock()
+
+	result := map[string]interface{}{}
+	switch {
+	case !ok:
+		result["errors"] = []map[string]interface{}{
+			{"message": fmt.Sprintf("no response scripted for %s", request.Field)},
+		}
+	case resp.errors != nil:
+		result["errors"] = resp.errors
+		result["data"] = nil
+	default:
+		result["data"] = map[string]interface{}{
+			request.Field: resp.data,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,115 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	"emperror.dev/errors"
+	"github.com/MakeNowJust/heredoc"
+)
+
+type ServicePort struct {
+	Name       string `json:"name"`
+	Port       int    `json:"port"`
+	TargetPort int    `json:"targetPort"`
+	Protocol   string `json:"protocol"`
+}
+
+type MeshService struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Selector  map[string]string `json:"selector,omitempty"`
+	Ports     []ServicePort     `json:"ports"`
+}
+
+// ServicesRequest selects the services of the given namespaces, or of every namespace if Namespaces is empty
+type ServicesRequest struct {
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+type ServicesResponse []MeshService
+
+type ServiceRequest struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+const meshServiceFields = `
+		name
+		namespace
+		labels
+		selector
+		ports {
+		  name
+		  port
+		  targetPort
+		  protocol
+		}
+`
+
+func (c *client) Services(ctx context.Context, req ServicesRequest) (ServicesResponse, error) {
+	request := heredoc.Doc(`
+	  query services(
+		$input: ServicesInput!
+	  ) {
+		services(
+		  input: $input
+		) {` + meshServiceFields + `}
+	  }
+`)
+
+	r := c.NewRequest(request)
+	r.Var("input", req)
+
+	// run it and capture the response
+	var respData map[string]ServicesResponse
+	if err := c.query(ctx, r, &respData); err != nil {
+		return nil, err
+	}
+
+	return respData["services"], nil
+}
+
+func (c *client) Service(ctx context.Context, req ServiceRequest) (*MeshService, error) {
+	request := heredoc.Doc(`
+	  query service(
+		$input: ServiceInput!
+	  ) {
+		service(
+		  input: $input
+		) {` + meshServiceFields + `}
+	  }
+`)
+
+	r := c.NewRequest(request)
+	r.Var("input", req)
+
+	// run it and capture the response
+	var respData map[string]*MeshService
+	if err := c.query(ctx, r, &respData); err != nil {
+		return nil, err
+	}
+
+	if respData["service"] == nil {
+		return nil, errors.WithStack(NotFoundError{
+			Message: fmt.Sprintf("service '%s/%s' not found", req.Namespace, req.Name),
+		})
+	}
+
+	return respData["service"], nil
+}
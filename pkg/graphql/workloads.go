@@ -0,0 +1,86 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+
+	"github.com/MakeNowJust/heredoc"
+)
+
+type MeshPod struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Phase     string `json:"phase"`
+	Ready     bool   `json:"ready"`
+	Restarts  int    `json:"restarts"`
+	NodeName  string `json:"nodeName"`
+	PodIP     string `json:"podIP"`
+}
+
+type MeshWorkload struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Kind      string            `json:"kind"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Replicas  int               `json:"replicas"`
+	Pods      []MeshPod         `json:"pods"`
+}
+
+// WorkloadsRequest selects the workloads of a namespace, or only the ones behind a service if Service is set
+type WorkloadsRequest struct {
+	Namespace string `json:"namespace"`
+	Service   string `json:"service,omitempty"`
+}
+
+type WorkloadsResponse []MeshWorkload
+
+func (c *client) Workloads(ctx context.Context, req WorkloadsRequest) (WorkloadsResponse, error) {
+	request := heredoc.Doc(`
+	  query workloads(
+		$input: WorkloadsInput!
+	  ) {
+		workloads(
+		  input: $input
+		) {
+		  name
+		  namespace
+		  kind
+		  labels
+		  replicas
+		  pods {
+			name
+			namespace
+			phase
+			ready
+			restarts
+			nodeName
+			podIP
+		  }
+		}
+	  }
+`)
+
+	r := c.NewRequest(request)
+	r.Var("input", req)
+
+	// run it and capture the response
+	var respData map[string]WorkloadsResponse
+	if err := c.query(ctx, r, &respData); err != nil {
+		return nil, err
+	}
+
+	return respData["workloads"], nil
+}
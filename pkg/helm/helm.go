@@ -68,8 +68,8 @@ func Render(fs http.FileSystem, values string, releaseOptions ReleaseOptions, ch
 	renderOpts := renderutil.Options{
 		ReleaseOptions: chartutil.ReleaseOptions{
 			Name:      releaseOptions.Name,
-			IsInstall: true,
-			IsUpgrade: false,
+			IsInstall: releaseOptions.IsInstall,
+			IsUpgrade: releaseOptions.IsUpgrade,
 			Time:      timeconv.Now(),
 			Namespace: releaseOptions.Namespace,
 		},
@@ -15,7 +15,14 @@
 package helm
 
 import (
+	"io/ioutil"
+	"os"
+
+	"emperror.dev/errors"
+	"github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/helm/pkg/strvals"
+	"sigs.k8s.io/yaml"
 )
 
 type Image struct {
@@ -45,3 +52,127 @@ type EnvironmentVariables struct {
 		ConfigMapKeyRef corev1.ConfigMapKeySelector `json:"configMapKeyRef"`
 	} `json:"envConfigMaps"`
 }
+
+// ValueOverrides are the values given by the user on top of the defaults of a chart
+type ValueOverrides struct {
+	ValueFiles []string
+	Values     []string
+}
+
+// AddFlags adds the -f/--values and --set flags to the flag set
+func (o *ValueOverrides) AddFlags(flags *pflag.FlagSet) {
+	o.addFlags(flags, "f")
+}
+
+// AddFlagsWithoutShorthand adds the --values and --set flags to the flag set, for commands which use -f for something else
+func (o *ValueOverrides) AddFlagsWithoutShorthand(flags *pflag.FlagSet) {
+	o.addFlags(flags, "")
+}
+
+func (o *ValueOverrides) addFlags(flags *pflag.FlagSet, shorthand string) {
+	flags.StringArrayVarP(&o.ValueFiles, "values", shorthand, o.ValueFiles, "Values file to override the defaults with, - reads from the standard input, can be given multiple times (the last one takes precedence)")
+	flags.StringArrayVar(&o.Values, "set", o.Values, "Value to override the defaults with in key=value format, can be given multiple times or comma separated (takes precedence over values files)")
+}
+
+// Merge merges the overrides on top of the values with the same precedence as Helm: the values files in the given order,
+// then the --set values. The result is returned as YAML, which also keeps the keys that are not part of the type of the values,
+// and it is unmarshalled back into values, so its fields reflect the overrides as well.
+func (o ValueOverrides) Merge(values interface{}) (string, error) {
+	return o.MergeWithBase(values, "")
+}
+
+// MergeWithBase merges the overrides like Merge, but the base values in YAML format are merged on top of the values first,
+// e.g. to keep the values a previous release was rendered with
+func (o ValueOverrides) MergeWithBase(values interface{}, base string) (string, error) {
+	rawValues, err := yaml.Marshal(values)
+	if err != nil {
+		return "", errors.WrapIf(err, "could not marshal yaml values")
+	}
+
+	merged := make(map[string]interface{})
+	err = yaml.Unmarshal(rawValues, &merged)
+	if err != nil {
+		return "", errors.WrapIf(err, "could not unmarshal yaml values")
+	}
+
+	if base != "" {
+		current := make(map[string]interface{})
+		err = yaml.Unmarshal([]byte(base), &current)
+		if err != nil {
+			return "", errors.WrapIf(err, "could not parse base values")
+		}
+
+		merged = mergeValues(merged, current)
+	}
+
+	for _, filename := range o.ValueFiles {
+		data, err := readValuesFile(filename)
+		if err != nil {
+			return "", err
+		}
+
+		current := make(map[string]interface{})
+		err = yaml.Unmarshal(data, &current)
+		if err != nil {
+			return "", errors.WrapIfWithDetails(err, "could not parse values file", "filename", filename)
+		}
+
+		merged = mergeValues(merged, current)
+	}
+
+	for _, value := range o.Values {
+		err = strvals.ParseInto(value, merged)
+		if err != nil {
+			return "", errors.WrapIfWithDetails(err, "could not parse --set value", "value", value)
+		}
+	}
+
+	rawValues, err = yaml.Marshal(merged)
+	if err != nil {
+		return "", errors.WrapIf(err, "could not marshal yaml values")
+	}
+
+	err = yaml.Unmarshal(rawValues, values)
+	if err != nil {
+		return "", errors.WrapIf(err, "invalid values")
+	}
+
+	return string(rawValues), nil
+}
+
+func readValuesFile(filename string) ([]byte, error) {
+	var data []byte
+	var err error
+
+	if filename == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not read values file", "filename", filename)
+	}
+
+	return data, nil
+}
+
+// mergeValues merges src into dest recursively, the values of src take precedence
+func mergeValues(dest, src map[string]interface{}) map[string]interface{} {
+	for key, value := range src {
+		srcMap, ok := value.(map[string]interface{})
+		if !ok {
+			dest[key] = value
+			continue
+		}
+
+		destMap, ok := dest[key].(map[string]interface{})
+		if !ok {
+			dest[key] = value
+			continue
+		}
+
+		dest[key] = mergeValues(destMap, srcMap)
+	}
+
+	return dest
+}
@@ -0,0 +1,145 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+type testValues struct {
+	ReplicaCount int `json:"replicaCount"`
+	Jaeger       struct {
+		Persist          bool   `json:"persist"`
+		StorageClassName string `json:"storageClassName"`
+	} `json:"jaeger"`
+}
+
+func TestValueOverridesMerge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "values")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		err := ioutil.WriteFile(filename, []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	first := writeFile("first.yaml", "replicaCount: 2\njaeger:\n  persist: true\n  storageClassName: standard\n")
+	second := writeFile("second.yaml", "jaeger:\n  storageClassName: fast\n  nodeSelector:\n    disk: ssd\n")
+
+	tests := []struct {
+		name      string
+		base      string
+		overrides ValueOverrides
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "keeps the defaults without overrides",
+			want: "jaeger:\n  persist: false\n  storageClassName: default\nreplicaCount: 1\n",
+		},
+		{
+			name: "later files take precedence and unknown keys are kept",
+			overrides: ValueOverrides{
+				ValueFiles: []string{first, second},
+			},
+			want: "jaeger:\n  nodeSelector:\n    disk: ssd\n  persist: true\n  storageClassName: fast\nreplicaCount: 2\n",
+		},
+		{
+			name: "set values take precedence over files",
+			overrides: ValueOverrides{
+				ValueFiles: []string{first},
+				Values:     []string{"replicaCount=3,jaeger.storageClassName=slow"},
+			},
+			want: "jaeger:\n  persist: true\n  storageClassName: slow\nreplicaCount: 3\n",
+		},
+		{
+			name: "base values take precedence over the defaults",
+			base: "replicaCount: 2\njaeger:\n  persist: true\n  nodeSelector:\n    disk: ssd\n",
+			want: "jaeger:\n  nodeSelector:\n    disk: ssd\n  persist: true\n  storageClassName: default\nreplicaCount: 2\n",
+		},
+		{
+			name: "overrides take precedence over the base values",
+			base: "replicaCount: 2\njaeger:\n  storageClassName: standard\n",
+			overrides: ValueOverrides{
+				ValueFiles: []string{second},
+				Values:     []string{"replicaCount=3"},
+			},
+			want: "jaeger:\n  nodeSelector:\n    disk: ssd\n  persist: false\n  storageClassName: fast\nreplicaCount: 3\n",
+		},
+		{
+			name:    "fails for invalid base values",
+			base:    "replicaCount: [",
+			wantErr: true,
+		},
+		{
+			name: "fails for missing files",
+			overrides: ValueOverrides{
+				ValueFiles: []string{filepath.Join(dir, "missing.yaml")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails for values of the wrong type",
+			overrides: ValueOverrides{
+				Values: []string{"jaeger.persist=maybe"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			values := testValues{ReplicaCount: 1}
+			values.Jaeger.StorageClassName = "default"
+
+			rawValues, err := test.overrides.MergeWithBase(&values, test.base)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got values:\n%s", rawValues)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if rawValues != test.want {
+				t.Errorf("unexpected values:\n%s\nexpected:\n%s", rawValues, test.want)
+			}
+
+			// the typed values reflect the overrides as well
+			var want testValues
+			err = yaml.Unmarshal([]byte(test.want), &want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if values != want {
+				t.Errorf("expected typed values %+v, got %+v", want, values)
+			}
+		})
+	}
+}
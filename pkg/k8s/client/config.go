@@ -37,3 +37,18 @@ func GetConfigWithContext(kubeconfigPath, kubeContext string) (*rest.Config, err
 		NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).
 		ClientConfig()
 }
+
+// GetNamespaceWithContext returns the namespace set for the given context in the kubeconfig,
+// or the default namespace if it is not set
+func GetNamespaceWithContext(kubeconfigPath, kubeContext string) (string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfigPath != "" {
+		rules.ExplicitPath = kubeconfigPath
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	namespace, _, err := clientcmd.
+		NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).
+		Namespace()
+
+	return namespace, err
+}
@@ -0,0 +1,288 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+
+	"emperror.dev/errors"
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pmezard/go-difflib/difflib"
+	"istio.io/operator/pkg/object"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8smeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+)
+
+// DiffAction is what applying the desired state would do with an object
+type DiffAction string
+
+const (
+	DiffActionCreate    DiffAction = "create"
+	DiffActionUpdate    DiffAction = "update"
+	DiffActionDelete    DiffAction = "delete"
+	DiffActionUnchanged DiffAction = "unchanged"
+)
+
+// ObjectDiff is the difference between the live state of an object and its desired state
+type ObjectDiff struct {
+	Object *object.K8sObject
+	Name   string
+	Action DiffAction
+	// Diff is the change in unified format, it is empty for unchanged objects
+	Diff string
+}
+
+// DiffResources compares the desired objects with their live state. Previous objects which are not desired
+// any more, but still exist, are compared with their absence.
+func DiffResources(client k8sclient.Client, desired, previous object.K8sObjects) ([]ObjectDiff, error) {
+	diffs := make([]ObjectDiff, 0, len(desired))
+
+	desiredObjects := desired.ToMap()
+	for _, obj := range desired {
+		diff, err := diffResource(client, obj)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, diff)
+	}
+
+	for _, obj := range previous {
+		if _, ok := desiredObjects[obj.Hash()]; ok {
+			continue
+		}
+
+		actual, err := getLiveObject(client, obj.UnstructuredObject())
+		if err != nil {
+			return nil, err
+		}
+		if actual == nil {
+			continue
+		}
+
+		diff, err := unifiedDiff(getFormattedName(actual), actual.Object, nil)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, ObjectDiff{
+			Object: obj,
+			Name:   getFormattedName(actual),
+			Action: DiffActionDelete,
+			Diff:   diff,
+		})
+	}
+
+	return diffs, nil
+}
+
+// ListAppliedResources lists the objects of the given kinds in every namespace which match the labels and
+// were applied by the CLI, kinds which are not known by the API server are skipped
+func ListAppliedResources(cl k8sclient.Client, gvks []schema.GroupVersionKind, matchLabels map[string]string) (object.K8sObjects, error) {
+	objects := make(object.K8sObjects, 0)
+
+	for _, gvk := range gvks {
+		var list unstructured.UnstructuredList
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+		err := cl.List(context.Background(), &list, client.MatchingLabels(matchLabels))
+		if k8smeta.IsNoMatchError(err) || k8serrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not list resources", "gvk", gvk.String())
+		}
+
+		for i := range list.Items {
+			item := &list.Items[i]
+			if _, ok := item.GetAnnotations()[patch.LastAppliedConfig]; !ok {
+				continue
+			}
+			item.SetGroupVersionKind(gvk)
+			objects = append(objects, object.NewK8sObject(item, nil, nil))
+		}
+	}
+
+	return objects, nil
+}
+
+// GroupVersionKinds returns the distinct kinds of the objects
+func GroupVersionKinds(objects object.K8sObjects) []schema.GroupVersionKind {
+	gvks := make([]schema.GroupVersionKind, 0)
+	seen := make(map[schema.GroupVersionKind]bool)
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		if !seen[gvk] {
+			seen[gvk] = true
+			gvks = append(gvks, gvk)
+		}
+	}
+
+	return gvks
+}
+
+func diffResource(client k8sclient.Client, obj *object.K8sObject) (ObjectDiff, error) {
+	desired := obj.UnstructuredObject().DeepCopy()
+	name := getFormattedName(desired)
+
+	actual, err := getLiveObject(client, desired)
+	if err != nil {
+		return ObjectDiff{}, err
+	}
+
+	if actual == nil {
+		diff, err := unifiedDiff(name, nil, desired.Object)
+		if err != nil {
+			return ObjectDiff{}, err
+		}
+		return ObjectDiff{
+			Object: obj,
+			Name:   name,
+			Action: DiffActionCreate,
+			Diff:   diff,
+		}, nil
+	}
+
+	desired.SetResourceVersion(actual.GetResourceVersion())
+	patchResult, err := patch.DefaultPatchMaker.Calculate(actual, desired)
+	if err != nil {
+		return ObjectDiff{}, errors.WrapIfWithDetails(err, "could not match objects", "name", name)
+	}
+	if patchResult.IsEmpty() {
+		return ObjectDiff{
+			Object: obj,
+			Name:   name,
+			Action: DiffActionUnchanged,
+		}, nil
+	}
+
+	// the diff shows the live object before and after the patch, so fields set by the cluster are left out of it
+	patched, err := jsonpatch.MergePatch(patchResult.Current, patchResult.Patch)
+	if err != nil {
+		return ObjectDiff{}, errors.WrapIfWithDetails(err, "could not apply patch", "name", name)
+	}
+
+	var before, after map[string]interface{}
+	err = json.Unmarshal(patchResult.Current, &before)
+	if err != nil {
+		return ObjectDiff{}, errors.WrapIf(err, "could not unmarshal live object")
+	}
+	err = json.Unmarshal(patched, &after)
+	if err != nil {
+		return ObjectDiff{}, errors.WrapIf(err, "could not unmarshal patched object")
+	}
+
+	diff, err := unifiedDiff(name, before, after)
+	if err != nil {
+		return ObjectDiff{}, err
+	}
+
+	action := DiffActionUpdate
+	if diff == "" {
+		// only fields which are left out of the diff changed
+		action = DiffActionUnchanged
+	}
+
+	return ObjectDiff{
+		Object: obj,
+		Name:   name,
+		Action: action,
+		Diff:   diff,
+	}, nil
+}
+
+// getLiveObject returns the live state of the object, or nil if it does not exist
+func getLiveObject(client k8sclient.Client, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	actual := obj.DeepCopy()
+	err := client.Get(context.Background(), types.NamespacedName{
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+	}, actual)
+	if k8serrors.IsNotFound(err) || k8smeta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not get resource", "name", getFormattedName(obj))
+	}
+
+	return actual, nil
+}
+
+func unifiedDiff(name string, before, after map[string]interface{}) (string, error) {
+	a, err := diffableYAML(before)
+	if err != nil {
+		return "", err
+	}
+	b, err := diffableYAML(after)
+	if err != nil {
+		return "", err
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(a),
+		B:        splitLines(b),
+		FromFile: name + " (live)",
+		ToFile:   name + " (new)",
+		Context:  3,
+	})
+	if err != nil {
+		return "", errors.WrapIfWithDetails(err, "could not calculate diff", "name", name)
+	}
+
+	return diff, nil
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return difflib.SplitLines(s)
+}
+
+// diffableYAML returns the object in YAML format without the fields which are maintained by the cluster
+func diffableYAML(obj map[string]interface{}) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+
+	obj = (&unstructured.Unstructured{Object: obj}).DeepCopy().Object
+	delete(obj, "status")
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		for _, field := range []string{"resourceVersion", "uid", "creationTimestamp", "generation", "selfLink", "managedFields"} {
+			delete(metadata, field)
+		}
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			delete(annotations, patch.LastAppliedConfig)
+			if len(annotations) == 0 {
+				delete(metadata, "annotations")
+			}
+		}
+	}
+
+	y, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", errors.WrapIf(err, "could not marshal object")
+	}
+
+	return string(y), nil
+}
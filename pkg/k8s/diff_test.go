@@ -0,0 +1,117 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"istio.io/operator/pkg/object"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+)
+
+func TestDiffResources(t *testing.T) {
+	configMap := func(name, value string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "backyards-system",
+				Labels:    map[string]string{"app.kubernetes.io/instance": "backyards"},
+			},
+			Data: map[string]string{"key": value},
+		}
+	}
+	applied := func(cm *corev1.ConfigMap) runtime.Object {
+		err := patch.DefaultAnnotator.SetLastAppliedAnnotation(cm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cm
+	}
+	toObjects := func(cms ...*corev1.ConfigMap) object.K8sObjects {
+		objects := make(object.K8sObjects, 0, len(cms))
+		for _, cm := range cms {
+			u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			obj, err := object.ParseJSONToK8sObject(mustJSON(t, u))
+			if err != nil {
+				t.Fatal(err)
+			}
+			objects = append(objects, obj)
+		}
+		return objects
+	}
+
+	client := fake.NewFakeClientWithScheme(k8sclient.GetScheme(),
+		applied(configMap("unchanged", "a")),
+		applied(configMap("changed", "a")),
+		applied(configMap("removed", "a")),
+	)
+
+	desired := toObjects(configMap("unchanged", "a"), configMap("changed", "b"), configMap("created", "a"))
+	previous := toObjects(configMap("unchanged", "a"), configMap("changed", "a"), configMap("removed", "a"), configMap("already-deleted", "a"))
+
+	diffs, err := DiffResources(client, desired, previous)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		wantAction DiffAction
+		wantLines  []string
+	}{
+		{name: "configmap/unchanged", wantAction: DiffActionUnchanged},
+		{name: "configmap/changed", wantAction: DiffActionUpdate, wantLines: []string{"-  key: a", "+  key: b"}},
+		{name: "configmap/created", wantAction: DiffActionCreate, wantLines: []string{"+  key: a", "+kind: ConfigMap"}},
+		{name: "configmap/removed", wantAction: DiffActionDelete, wantLines: []string{"-  key: a", "-kind: ConfigMap"}},
+	}
+
+	if len(diffs) != len(tests) {
+		t.Fatalf("expected %d diffs, got %d", len(tests), len(diffs))
+	}
+	for i, test := range tests {
+		diff := diffs[i]
+		if diff.Name != test.name || diff.Action != test.wantAction {
+			t.Errorf("expected %s to %s, got %s to %s", test.name, test.wantAction, diff.Name, diff.Action)
+		}
+		for _, line := range test.wantLines {
+			if !strings.Contains(diff.Diff, line+"\n") {
+				t.Errorf("expected diff of %s to contain %q, got:\n%s", test.name, line, diff.Diff)
+			}
+		}
+		if strings.Contains(diff.Diff, patch.LastAppliedConfig) || strings.Contains(diff.Diff, "resourceVersion") {
+			t.Errorf("expected diff of %s not to contain fields maintained by the cluster, got:\n%s", test.name, diff.Diff)
+		}
+	}
+}
+
+func mustJSON(t *testing.T, obj interface{}) []byte {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
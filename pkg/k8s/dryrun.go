@@ -0,0 +1,216 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"emperror.dev/errors"
+	"github.com/spf13/pflag"
+	"istio.io/operator/pkg/object"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8smeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+)
+
+// DryRunStrategy is how the changes to the cluster are checked without making them
+type DryRunStrategy string
+
+const (
+	DryRunNone DryRunStrategy = "none"
+	// DryRunClient compares the objects with their live state in the cluster
+	DryRunClient DryRunStrategy = "client"
+	// DryRunServer sends the objects to the API server in dry run mode, so they are validated and admitted as well
+	DryRunServer DryRunStrategy = "server"
+)
+
+// AddFlag adds the --dry-run flag to the flag set, the client strategy is used if the flag is given without a value
+func (s *DryRunStrategy) AddFlag(flags *pflag.FlagSet) {
+	flags.Var(s, "dry-run", `Only report the changes which would be made to the cluster, one of "none", "client" or "server"`)
+	flags.Lookup("dry-run").NoOptDefVal = string(DryRunClient)
+}
+
+// Enabled returns whether the changes should only be checked
+func (s *DryRunStrategy) Enabled() bool {
+	return *s == DryRunClient || *s == DryRunServer
+}
+
+func (s *DryRunStrategy) String() string {
+	if *s == "" {
+		return string(DryRunNone)
+	}
+
+	return string(*s)
+}
+
+func (s *DryRunStrategy) Set(value string) error {
+	switch strategy := DryRunStrategy(value); strategy {
+	case DryRunNone, DryRunClient, DryRunServer:
+		*s = strategy
+		return nil
+	default:
+		return errors.Errorf(`invalid dry run strategy %q, must be "none", "client" or "server"`, value)
+	}
+}
+
+func (s *DryRunStrategy) Type() string {
+	return "string"
+}
+
+// DryRunResult is what applying or deleting an object would do
+type DryRunResult struct {
+	Name   string
+	Action DiffAction
+	// Error is the reason why the API server rejected the object
+	Error error
+	// Warning explains why the API server could not verify the object
+	Warning string
+}
+
+// Rejected returns whether the object was rejected by the API server
+func (r DryRunResult) Rejected() bool {
+	return r.Error != nil
+}
+
+// deleteDryRunAll sets the DryRun field of the raw delete options, there is no functional option for it
+var deleteDryRunAll client.DeleteOptionFunc = func(opts *client.DeleteOptions) {
+	if opts.Raw == nil {
+		opts.Raw = &metav1.DeleteOptions{}
+	}
+	opts.Raw.DryRun = []string{metav1.DryRunAll}
+}
+
+// DryRunResources returns what applying the objects would do without changing anything in the cluster
+func DryRunResources(client k8sclient.Client, objects object.K8sObjects, strategy DryRunStrategy) ([]DryRunResult, error) {
+	if strategy == DryRunServer {
+		return dryRunResourcesOnServer(client, objects)
+	}
+
+	diffs, err := DiffResources(client, objects, nil)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not compare resources with the cluster")
+	}
+
+	results := make([]DryRunResult, 0, len(diffs))
+	for _, diff := range diffs {
+		results = append(results, DryRunResult{
+			Name:   diff.Name,
+			Action: diff.Action,
+		})
+	}
+
+	return results, nil
+}
+
+// DryRunDeleteResources returns what deleting the objects would do without changing anything in the cluster
+func DryRunDeleteResources(cl k8sclient.Client, objects object.K8sObjects, strategy DryRunStrategy) ([]DryRunResult, error) {
+	results := make([]DryRunResult, 0, len(objects))
+	for _, obj := range objects {
+		desired := obj.UnstructuredObject().DeepCopy()
+		result := DryRunResult{
+			Name:   getFormattedName(desired),
+			Action: DiffActionDelete,
+		}
+
+		actual, err := getLiveObject(cl, desired)
+		if err != nil {
+			return nil, err
+		}
+		if actual == nil {
+			result.Action = DiffActionUnchanged
+		} else if strategy == DryRunServer {
+			err = cl.Delete(context.Background(), actual, deleteDryRunAll)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				result.Error = err
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func dryRunResourcesOnServer(cl k8sclient.Client, objects object.K8sObjects) ([]DryRunResult, error) {
+	results := make([]DryRunResult, 0, len(objects))
+	for _, obj := range objects {
+		desired := obj.UnstructuredObject().DeepCopy()
+		result := DryRunResult{
+			Name: getFormattedName(desired),
+		}
+
+		actual, err := getLiveObject(cl, desired)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(desired); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not set last applied annotation", "name", result.Name)
+		}
+
+		if actual == nil {
+			result.Action = DiffActionCreate
+			err = cl.Create(context.Background(), desired, client.CreateDryRunAll)
+		} else {
+			var patchResult *patch.PatchResult
+			desired.SetResourceVersion(actual.GetResourceVersion())
+			patchResult, err = patch.DefaultPatchMaker.Calculate(actual, desired)
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not match objects", "name", result.Name)
+			}
+			if patchResult.IsEmpty() {
+				result.Action = DiffActionUnchanged
+				results = append(results, result)
+				continue
+			}
+
+			result.Action = DiffActionUpdate
+			err = cl.Update(context.Background(), prepareObjectBeforeUpdate(actual, desired), client.UpdateDryRunAll)
+		}
+
+		if err != nil {
+			result.Warning = getUnverifiableReason(desired, err)
+			if result.Warning == "" {
+				result.Error = err
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// getUnverifiableReason returns why the object could not be verified if it depends on something which does not exist
+// yet, but would be created by the same install, e.g. its namespace or custom resource definition
+func getUnverifiableReason(obj *unstructured.Unstructured, err error) string {
+	if k8smeta.IsNoMatchError(err) {
+		return fmt.Sprintf("not verified, kind %s is not known by the API server yet", obj.GroupVersionKind().GroupKind())
+	}
+
+	if statusErr, ok := errors.Cause(err).(*k8serrors.StatusError); ok && k8serrors.IsNotFound(err) {
+		if details := statusErr.ErrStatus.Details; details != nil && details.Kind == "namespaces" {
+			return fmt.Sprintf("not verified, namespace %s does not exist yet", details.Name)
+		}
+	}
+
+	return ""
+}
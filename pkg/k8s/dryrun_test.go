@@ -0,0 +1,178 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"istio.io/operator/pkg/object"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+)
+
+// rejectingClient rejects the creation and update of the objects with the given names, like a validating admission
+// webhook would
+type rejectingClient struct {
+	client.Client
+	rejected map[string]error
+}
+
+func (c *rejectingClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOptionFunc) error {
+	if o, ok := obj.(metav1.Object); ok {
+		if err, ok := c.rejected[o.GetName()]; ok {
+			return err
+		}
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *rejectingClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOptionFunc) error {
+	if o, ok := obj.(metav1.Object); ok {
+		if err, ok := c.rejected[o.GetName()]; ok {
+			return err
+		}
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func TestDryRunStrategyFlag(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    DryRunStrategy
+		wantErr bool
+	}{
+		{args: []string{}, want: ""},
+		{args: []string{"--dry-run"}, want: DryRunClient},
+		{args: []string{"--dry-run=server"}, want: DryRunServer},
+		{args: []string{"--dry-run=none"}, want: DryRunNone},
+		{args: []string{"--dry-run=always"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		var strategy DryRunStrategy
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		strategy.AddFlag(flags)
+
+		err := flags.Parse(test.args)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%v: unexpected error: %v", test.args, err)
+			continue
+		}
+		if !test.wantErr && strategy != test.want {
+			t.Errorf("%v: expected %q, got %q", test.args, test.want, strategy)
+		}
+	}
+}
+
+func TestDryRunResourcesOnServer(t *testing.T) {
+	configMap := func(name, value string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "backyards-system",
+			},
+			Data: map[string]string{"key": value},
+		}
+	}
+	applied := func(cm *corev1.ConfigMap) runtime.Object {
+		err := patch.DefaultAnnotator.SetLastAppliedAnnotation(cm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cm
+	}
+
+	objects := make(object.K8sObjects, 0)
+	for _, cm := range []*corev1.ConfigMap{configMap("unchanged", "a"), configMap("changed", "b"), configMap("created", "a"), configMap("invalid", "a"), configMap("forbidden", "b")} {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		obj, err := object.ParseJSONToK8sObject(mustJSON(t, u))
+		if err != nil {
+			t.Fatal(err)
+		}
+		objects = append(objects, obj)
+	}
+
+	invalid := k8serrors.NewInvalid(schema.GroupKind{Kind: "ConfigMap"}, "invalid", field.ErrorList{
+		field.Invalid(field.NewPath("data", "key"), "a", "must not be a"),
+	})
+	forbidden := k8serrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "forbidden", errors.New("not allowed"))
+	var cl k8sclient.Client = &rejectingClient{
+		Client: fake.NewFakeClientWithScheme(k8sclient.GetScheme(),
+			applied(configMap("unchanged", "a")),
+			applied(configMap("changed", "a")),
+			applied(configMap("forbidden", "a")),
+		),
+		rejected: map[string]error{"invalid": invalid, "forbidden": forbidden},
+	}
+
+	results, err := DryRunResources(cl, objects, DryRunServer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		wantAction   DiffAction
+		wantRejected bool
+	}{
+		{name: "configmap/unchanged", wantAction: DiffActionUnchanged},
+		{name: "configmap/changed", wantAction: DiffActionUpdate},
+		{name: "configmap/created", wantAction: DiffActionCreate},
+		{name: "configmap/invalid", wantAction: DiffActionCreate, wantRejected: true},
+		{name: "configmap/forbidden", wantAction: DiffActionUpdate, wantRejected: true},
+	}
+
+	if len(results) != len(tests) {
+		t.Fatalf("expected %d results, got %d", len(tests), len(results))
+	}
+	for i, test := range tests {
+		result := results[i]
+		if result.Name != test.name || result.Action != test.wantAction || result.Rejected() != test.wantRejected {
+			t.Errorf("expected %s to %s (rejected: %t), got %s to %s (rejected: %t)",
+				test.name, test.wantAction, test.wantRejected, result.Name, result.Action, result.Rejected())
+		}
+	}
+
+	// nothing is changed in the cluster
+	var cm corev1.ConfigMap
+	err = cl.Get(context.Background(), types.NamespacedName{Name: "changed", Namespace: "backyards-system"}, &cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.Data["key"] != "a" {
+		t.Errorf("expected configmap/changed not to be updated, got %q", cm.Data["key"])
+	}
+	err = cl.Get(context.Background(), types.NamespacedName{Name: "created", Namespace: "backyards-system"}, &cm)
+	if !k8serrors.IsNotFound(err) {
+		t.Errorf("expected configmap/created not to be created, got %v", err)
+	}
+}
@@ -21,10 +21,13 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
 
 	"emperror.dev/errors"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/portforward"
@@ -34,74 +37,239 @@ import (
 	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
 )
 
+const (
+	reconnectMinBackoff = time.Second
+	reconnectMaxBackoff = 30 * time.Second
+)
+
+// podSelector selects the pod to forward to and the port of the pod, it is called again when the connection is lost
+type podSelector func() (podName string, remotePort int, err error)
+
+// forwarder forwards the local port to the selected pod until the connection is lost or the port forward is stopped
+type forwarder func(readyChannel chan struct{}) error
+
 type Portforward struct {
-	namespace  string
+	namespace string
+	selector  podSelector
+	forward   forwarder
+	localPort int
+
+	// the target is reselected by the supervisor goroutine when the connection is lost
+	mu         sync.Mutex
 	podname    string
-	localPort  int
 	remotePort int
 	url        *url.URL
 
 	stopChannel  chan struct{}
+	stopOnce     sync.Once
 	readyChannel chan struct{}
 
-	config *rest.Config
+	k8sClient k8sclient.Client
+	clientset kubernetes.Interface
+	config    *rest.Config
 }
 
+// New returns a port forward to a ready pod which matches the labels
 func New(k8sClient k8sclient.Client, config *rest.Config, matchLabels map[string]string, namespace string, localPort, remotePort int) (*Portforward, error) {
-	var pods v1.PodList
-	err := k8sClient.List(context.Background(), &pods, client.InNamespace(namespace), client.MatchingLabels(matchLabels))
+	pf, err := newPortforward(k8sClient, config, namespace, localPort)
 	if err != nil {
-		return nil, errors.WrapIfWithDetails(err, "could not list pods", "namespace", namespace)
+		return nil, err
 	}
 
-	podName := ""
-	for _, pod := range pods.Items {
-		if pod.Status.Phase == v1.PodRunning {
-			podName = pod.Name
-			break
-		}
+	pf.selector = func() (string, int, error) {
+		podName, err := pf.selectPodByLabels(matchLabels)
+		return podName, remotePort, err
+	}
+
+	err = pf.init()
+	if err != nil {
+		return nil, err
 	}
 
-	if podName == "" {
-		return nil, errors.NewWithDetails("no running pods found", "matchLabels", matchLabels, "namespace", namespace)
+	return pf, nil
+}
+
+// NewForService returns a port forward to a ready pod behind the service, the port of the pod is resolved
+// from the endpoints of the service instead of assuming it is the same as the service port.
+// If podName is set, that pod is used as long as it is a ready endpoint of the service.
+func NewForService(k8sClient k8sclient.Client, config *rest.Config, service types.NamespacedName, podName string, localPort, servicePort int) (*Portforward, error) {
+	pf, err := newPortforward(k8sClient, config, service.Namespace, localPort)
+	if err != nil {
+		return nil, err
 	}
 
+	pf.selector = func() (string, int, error) {
+		return pf.selectPodByService(service, podName, servicePort)
+	}
+
+	err = pf.init()
+	if err != nil {
+		return nil, err
+	}
+
+	return pf, nil
+}
+
+func newPortforward(k8sClient k8sclient.Client, config *rest.Config, namespace string, localPort int) (*Portforward, error) {
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, errors.WrapIf(err, "could not get k8s clientset")
 	}
 
-	req := clientset.CoreV1().RESTClient().Post().
+	pf := &Portforward{
+		namespace: namespace,
+		localPort: localPort,
+
+		stopChannel:  make(chan struct{}, 1),
+		readyChannel: make(chan struct{}),
+
+		k8sClient: k8sClient,
+		clientset: clientset,
+		config:    config,
+	}
+	pf.forward = pf.run
+
+	return pf, nil
+}
+
+func (pf *Portforward) init() error {
+	err := pf.selectPod()
+	if err != nil {
+		return err
+	}
+
+	if pf.localPort == 0 {
+		pf.localPort, err = getEphemeralPort()
+		if err != nil {
+			return errors.WrapIf(err, "could not get ephemeral port")
+		}
+	}
+
+	return nil
+}
+
+// selectPod selects the pod and the remote port of the port forward
+func (pf *Portforward) selectPod() error {
+	podName, remotePort, err := pf.selector()
+	if err != nil {
+		return err
+	}
+
+	req := pf.clientset.CoreV1().RESTClient().Post().
 		Resource("pods").
-		Namespace(namespace).
+		Namespace(pf.namespace).
 		Name(podName).
 		SubResource("portforward")
 
-	if localPort == 0 {
-		localPort, err = getEphemeralPort()
-		if err != nil {
-			return nil, errors.WrapIf(err, "could not get ephemeral port")
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	pf.podname = podName
+	pf.remotePort = remotePort
+	pf.url = req.URL()
+
+	return nil
+}
+
+// target returns the currently selected pod, remote port and port forward URL
+func (pf *Portforward) target() (string, int, *url.URL) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	return pf.podname, pf.remotePort, pf.url
+}
+
+func (pf *Portforward) podID() string {
+	podName, _, _ := pf.target()
+	return pf.namespace + "/" + podName
+}
+
+// selectPodByLabels selects a ready pod which matches the labels
+func (pf *Portforward) selectPodByLabels(matchLabels map[string]string) (string, error) {
+	var pods v1.PodList
+	err := pf.k8sClient.List(context.Background(), &pods, client.InNamespace(pf.namespace), client.MatchingLabels(matchLabels))
+	if err != nil {
+		return "", errors.WrapIfWithDetails(err, "could not list pods", "namespace", pf.namespace)
+	}
+
+	for _, pod := range pods.Items {
+		if isPodReady(pod) {
+			return pod.Name, nil
 		}
 	}
 
-	return &Portforward{
-		namespace:  namespace,
-		podname:    podName,
-		localPort:  localPort,
-		remotePort: remotePort,
-		url:        req.URL(),
+	return "", errors.NewWithDetails("no ready pods found", "matchLabels", matchLabels, "namespace", pf.namespace)
+}
 
-		stopChannel:  make(chan struct{}, 1),
-		readyChannel: make(chan struct{}),
+// selectPodByService selects a ready pod from the endpoints of the service and resolves the target port of the service port
+func (pf *Portforward) selectPodByService(service types.NamespacedName, podName string, servicePort int) (string, int, error) {
+	var svc v1.Service
+	err := pf.k8sClient.Get(context.Background(), service, &svc)
+	if err != nil {
+		return "", 0, errors.WrapIfWithDetails(err, "could not get service", "service", service)
+	}
+
+	var port *v1.ServicePort
+	for i, p := range svc.Spec.Ports {
+		if int(p.Port) == servicePort {
+			port = &svc.Spec.Ports[i]
+			break
+		}
+	}
+	if port == nil {
+		return "", 0, errors.NewWithDetails("service port not found", "service", service, "port", servicePort)
+	}
+
+	var endpoints v1.Endpoints
+	err = pf.k8sClient.Get(context.Background(), service, &endpoints)
+	if err != nil {
+		return "", 0, errors.WrapIfWithDetails(err, "could not get endpoints", "service", service)
+	}
+
+	// only the ready addresses are considered, the pods are checked as well since the endpoints can be stale
+	for _, subset := range endpoints.Subsets {
+		targetPort := 0
+		for _, p := range subset.Ports {
+			if p.Name == port.Name && p.Protocol == port.Protocol {
+				targetPort = int(p.Port)
+				break
+			}
+		}
+		if targetPort == 0 {
+			continue
+		}
 
-		config: config,
-	}, nil
+		for _, address := range subset.Addresses {
+			if address.TargetRef == nil || address.TargetRef.Kind != "Pod" {
+				continue
+			}
+			if podName != "" && address.TargetRef.Name != podName {
+				continue
+			}
+
+			var pod v1.Pod
+			err = pf.k8sClient.Get(context.Background(), types.NamespacedName{Name: address.TargetRef.Name, Namespace: service.Namespace}, &pod)
+			if err != nil {
+				log.Debugf("could not get pod %s/%s: %s", service.Namespace, address.TargetRef.Name, err)
+				continue
+			}
+			if isPodReady(pod) {
+				return pod.Name, targetPort, nil
+			}
+		}
+	}
+
+	if podName != "" {
+		return "", 0, errors.NewWithDetails("pod is not a ready endpoint of the service", "pod", podName, "service", service)
+	}
+
+	return "", 0, errors.NewWithDetails("no ready endpoints found", "service", service, "port", servicePort)
 }
 
 func (pf *Portforward) Stop() {
-	if pf.stopChannel != nil {
+	pf.stopOnce.Do(func() {
 		close(pf.stopChannel)
-	}
+	})
 }
 
 func (pf *Portforward) WaitForStop() {
@@ -113,21 +281,31 @@ func (pf *Portforward) GetURL(path string) string {
 	return fmt.Sprintf("http://127.0.0.1:%d%s", pf.localPort, path)
 }
 
+// Run starts the port forwarding, which is stopped when the connection to the pod is lost
 func (pf *Portforward) Run() error {
-	failure := make(chan error)
+	return pf.start(false)
+}
+
+// RunSupervised starts the port forwarding like Run, but when the connection to the pod is lost
+// it selects a ready pod again and re-establishes the port forwarding on the same local port,
+// until Stop is called
+func (pf *Portforward) RunSupervised() error {
+	return pf.start(true)
+}
+
+func (pf *Portforward) start(supervise bool) error {
+	failure := make(chan error, 1)
 
 	go func() {
-		if err := pf.run(); err != nil {
+		err := pf.forward(pf.readyChannel)
+		if err != nil && !isClosed(pf.readyChannel) {
 			failure <- err
+		} else if supervise {
+			pf.reconnect(err)
 		}
 
-		select {
-		case <-pf.stopChannel:
-			// stopCh was closed, do nothing
-		default:
-			// pf.run() returned for some other reason, close stopCh
-			pf.Stop()
-		}
+		// stop the port forward if it returned for some other reason than Stop
+		pf.Stop()
 	}()
 
 	select {
@@ -141,16 +319,67 @@ func (pf *Portforward) Run() error {
 	return nil
 }
 
-func (pf *Portforward) run() error {
+// reconnect re-establishes the lost port forwarding with exponential backoff until the port forward is stopped
+func (pf *Portforward) reconnect(cause error) {
+	backoff := reconnectMinBackoff
+	lost := true
+
+	for !isClosed(pf.stopChannel) {
+		if lost {
+			log.WithField("pod", pf.podID()).Warn("port forward connection lost, reconnecting")
+			lost = false
+		} else {
+			log.Warnf("could not reconnect port forward: %s, retrying in %s", cause, backoff)
+		}
+
+		select {
+		case <-pf.stopChannel:
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > reconnectMaxBackoff {
+			backoff = reconnectMaxBackoff
+		}
+
+		cause = pf.selectPod()
+		if cause != nil {
+			continue
+		}
+
+		ready := make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			done <- pf.forward(ready)
+		}()
+
+		select {
+		case <-ready:
+			log.WithField("pod", pf.podID()).Info("port forward reconnected")
+			backoff = reconnectMinBackoff
+			lost = true
+			<-done
+		case cause = <-done:
+			if cause == nil {
+				cause = errors.New("connection closed before the port forward was ready")
+			}
+		}
+	}
+}
+
+func (pf *Portforward) run(readyChannel chan struct{}) error {
 	var err error
 
+	_, remotePort, podURL := pf.target()
+
 	transport, upgrader, err := spdy.RoundTripperFor(pf.config)
 	if err != nil {
 		return errors.WrapIf(err, "could not initialize round tripper")
 	}
 
-	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", pf.url)
-	fw, err := portforward.New(dialer, []string{fmt.Sprintf("%d:%d", pf.localPort, pf.remotePort)}, pf.stopChannel, pf.readyChannel, ioutil.Discard, ioutil.Discard)
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", podURL)
+	fw, err := portforward.New(dialer, []string{fmt.Sprintf("%d:%d", pf.localPort, remotePort)}, pf.stopChannel, readyChannel, ioutil.Discard, ioutil.Discard)
 	if err != nil {
 		return errors.WrapIf(err, "could not create port forwarder")
 	}
@@ -163,6 +392,39 @@ func (pf *Portforward) run() error {
 	return nil
 }
 
+// isPodReady checks whether the pod is running, is not being deleted and all of its containers are ready
+func isPodReady(pod v1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != v1.PodRunning {
+		return false
+	}
+
+	if len(pod.Status.ContainerStatuses) == 0 {
+		return false
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if !status.Ready {
+			return false
+		}
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
 // getEphemeralPort selects a port for the port-forwarding
 // It binds to a free ephemeral port and returns the port number
 func getEphemeralPort() (int, error) {
@@ -0,0 +1,298 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+)
+
+type podState int
+
+const (
+	podReady podState = iota
+	podNotReady
+	podPending
+	podCrashLooping
+	podTerminating
+)
+
+func testPod(name string, state podState) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "backyards-system",
+			Labels:    map[string]string{"app": "igw"},
+		},
+		Status: v1.PodStatus{
+			Phase:             v1.PodRunning,
+			Conditions:        []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+			ContainerStatuses: []v1.ContainerStatus{{Name: "istio-proxy", Ready: true}},
+		},
+	}
+
+	switch state {
+	case podNotReady:
+		pod.Status.Conditions[0].Status = v1.ConditionFalse
+	case podPending:
+		pod.Status.Phase = v1.PodPending
+	case podCrashLooping:
+		// the ready condition lags behind the container status
+		pod.Status.ContainerStatuses[0].Ready = false
+		pod.Status.ContainerStatuses[0].RestartCount = 5
+	case podTerminating:
+		now := metav1.Now()
+		pod.DeletionTimestamp = &now
+	}
+
+	return pod
+}
+
+func TestNewSelectsReadyPod(t *testing.T) {
+	tests := []struct {
+		name    string
+		pods    []runtime.Object
+		wantPod string
+	}{
+		{
+			name: "skips pods which are not ready",
+			pods: []runtime.Object{
+				testPod("igw-a", podNotReady),
+				testPod("igw-b", podPending),
+				testPod("igw-c", podCrashLooping),
+				testPod("igw-d", podTerminating),
+				testPod("igw-e", podReady),
+			},
+			wantPod: "igw-e",
+		},
+		{
+			name: "fails without ready pods",
+			pods: []runtime.Object{
+				testPod("igw-a", podNotReady),
+				testPod("igw-b", podTerminating),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(k8sclient.GetScheme(), test.pods...)
+
+			pf, err := New(client, &rest.Config{Host: "https://127.0.0.1:6443"}, map[string]string{"app": "igw"}, "backyards-system", 0, 80)
+			if test.wantPod == "" {
+				if err == nil {
+					t.Fatalf("expected an error, port forward selected pod %s", pf.podname)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if pf.podname != test.wantPod {
+				t.Errorf("expected pod %s, got %s", test.wantPod, pf.podname)
+			}
+			if pf.localPort == 0 {
+				t.Error("expected an ephemeral local port to be selected")
+			}
+		})
+	}
+}
+
+func TestNewForServiceResolvesEndpoints(t *testing.T) {
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "igw",
+			Namespace: "backyards-system",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "status-port", Port: 15020, Protocol: v1.ProtocolTCP, TargetPort: intstr.FromInt(15020)},
+				{Name: "http2", Port: 80, Protocol: v1.ProtocolTCP, TargetPort: intstr.FromString("http")},
+			},
+		},
+	}
+	address := func(pod string) v1.EndpointAddress {
+		return v1.EndpointAddress{IP: "10.0.0.1", TargetRef: &v1.ObjectReference{Kind: "Pod", Name: pod, Namespace: "backyards-system"}}
+	}
+	endpoints := &v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "igw",
+			Namespace: "backyards-system",
+		},
+		Subsets: []v1.EndpointSubset{
+			{
+				// igw-a is stale in the endpoints, it is already terminating
+				Addresses:         []v1.EndpointAddress{address("igw-a"), address("igw-b"), address("igw-c")},
+				NotReadyAddresses: []v1.EndpointAddress{address("igw-d")},
+				Ports: []v1.EndpointPort{
+					{Name: "status-port", Port: 15020, Protocol: v1.ProtocolTCP},
+					{Name: "http2", Port: 8080, Protocol: v1.ProtocolTCP},
+				},
+			},
+		},
+	}
+	objects := []runtime.Object{
+		service,
+		endpoints,
+		testPod("igw-a", podTerminating),
+		testPod("igw-b", podReady),
+		testPod("igw-c", podReady),
+		testPod("igw-d", podNotReady),
+	}
+
+	tests := []struct {
+		name           string
+		podName        string
+		servicePort    int
+		wantPod        string
+		wantRemotePort int
+	}{
+		{
+			name:           "selects a ready endpoint and resolves the target port",
+			servicePort:    80,
+			wantPod:        "igw-b",
+			wantRemotePort: 8080,
+		},
+		{
+			name:           "uses the pinned pod",
+			podName:        "igw-c",
+			servicePort:    15020,
+			wantPod:        "igw-c",
+			wantRemotePort: 15020,
+		},
+		{
+			name:        "fails if the pinned pod is not a ready endpoint",
+			podName:     "igw-d",
+			servicePort: 80,
+		},
+		{
+			name:        "fails for unknown service ports",
+			servicePort: 443,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(k8sclient.GetScheme(), objects...)
+
+			pf, err := NewForService(client, &rest.Config{Host: "https://127.0.0.1:6443"}, types.NamespacedName{Name: "igw", Namespace: "backyards-system"}, test.podName, 0, test.servicePort)
+			if test.wantPod == "" {
+				if err == nil {
+					t.Fatalf("expected an error, port forward selected pod %s", pf.podname)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if pf.podname != test.wantPod {
+				t.Errorf("expected pod %s, got %s", test.wantPod, pf.podname)
+			}
+			if pf.remotePort != test.wantRemotePort {
+				t.Errorf("expected remote port %d, got %d", test.wantRemotePort, pf.remotePort)
+			}
+		})
+	}
+}
+
+func TestRunSupervisedReconnects(t *testing.T) {
+	client := fake.NewFakeClientWithScheme(k8sclient.GetScheme(), testPod("igw-a", podReady))
+
+	pf, err := New(client, &rest.Config{Host: "https://127.0.0.1:6443"}, map[string]string{"app": "igw"}, "backyards-system", 0, 80)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer pf.Stop()
+
+	// the fake forwarder listens on the local port like the real one and returns when the stream is dropped
+	drop := make(chan struct{})
+	forwards := make(chan string, 2)
+	pf.forward = func(readyChannel chan struct{}) error {
+		podName, _, _ := pf.target()
+
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", pf.localPort))
+		if err != nil {
+			return err
+		}
+		defer listener.Close()
+
+		close(readyChannel)
+		forwards <- podName
+
+		select {
+		case <-drop:
+		case <-pf.stopChannel:
+		}
+
+		return nil
+	}
+
+	err = pf.RunSupervised()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	waitForForward := func(wantPod string) {
+		select {
+		case podName := <-forwards:
+			if podName != wantPod {
+				t.Fatalf("expected forward to pod %s, got %s", wantPod, podName)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("port forward to pod %s was not established", wantPod)
+		}
+
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", pf.localPort))
+		if err != nil {
+			t.Fatalf("could not connect to the local port: %v", err)
+		}
+		conn.Close()
+	}
+
+	waitForForward("igw-a")
+
+	// the pod is replaced and the stream is dropped
+	err = client.Delete(context.Background(), testPod("igw-a", podReady))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.Create(context.Background(), testPod("igw-b", podReady))
+	if err != nil {
+		t.Fatal(err)
+	}
+	drop <- struct{}{}
+
+	waitForForward("igw-b")
+
+	pf.Stop()
+	pf.WaitForStop()
+}
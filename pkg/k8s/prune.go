@@ -0,0 +1,95 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"emperror.dev/errors"
+	log "github.com/sirupsen/logrus"
+	"istio.io/operator/pkg/object"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/banzaicloud/backyards-cli/pkg/helm"
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+)
+
+const (
+	ReleaseLabel   = "backyards.banzaicloud.io/release"
+	ComponentLabel = "backyards.banzaicloud.io/component"
+	NamespaceLabel = "backyards.banzaicloud.io/namespace"
+)
+
+// unprunableKinds are never pruned, deleting them would delete every resource in them as well
+var unprunableKinds = map[string]bool{
+	"CustomResourceDefinition": true,
+	"Namespace":                true,
+}
+
+// OwnershipLabels returns the labels which mark an object as part of a component of a release installed into the
+// namespace, so that installs of the same release into different namespaces do not own each other's objects
+func OwnershipLabels(release, component, namespace string) map[string]string {
+	return map[string]string{
+		ReleaseLabel:   release,
+		ComponentLabel: component,
+		NamespaceLabel: namespace,
+	}
+}
+
+// LabelResources adds the labels to each of the objects
+func LabelResources(objects object.K8sObjects, labels map[string]string) {
+	for _, obj := range objects {
+		obj.AddLabels(labels)
+	}
+}
+
+// PruneResources deletes the objects of the given kinds which are labeled as owned by the labels, but are not part of
+// the objects any more. In dry run mode nothing is deleted. It returns the objects which are pruned.
+func PruneResources(client k8sclient.Client, objects object.K8sObjects, gvks []schema.GroupVersionKind, labels map[string]string, dryRun bool) (object.K8sObjects, error) {
+	owned, err := ListAppliedResources(client, gvks, labels)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list owned resources")
+	}
+
+	pruned := getPrunableResources(owned, objects)
+
+	if dryRun {
+		return pruned, nil
+	}
+
+	err = DeleteResources(client, pruned)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not prune resources")
+	}
+
+	return pruned, nil
+}
+
+// getPrunableResources returns the owned objects which are not part of the objects in uninstall order
+func getPrunableResources(owned, objects object.K8sObjects) object.K8sObjects {
+	desired := objects.ToMap()
+	pruned := make(object.K8sObjects, 0)
+	for _, obj := range owned {
+		if _, ok := desired[obj.Hash()]; ok {
+			continue
+		}
+		if unprunableKinds[obj.Kind] {
+			log.Warnf("%s is not part of the release any more, but it is not pruned", getFormattedName(obj.UnstructuredObject()))
+			continue
+		}
+		pruned = append(pruned, obj)
+	}
+	pruned.Sort(helm.UninstallObjectOrder())
+
+	return pruned
+}
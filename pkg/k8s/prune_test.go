@@ -0,0 +1,177 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"istio.io/operator/pkg/object"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+)
+
+func TestGetPrunableResources(t *testing.T) {
+	parse := func(manifest string) object.K8sObjects {
+		objects, err := object.ParseK8sObjectsFromYAMLManifest(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return objects
+	}
+
+	owned := parse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kept
+  namespace: backyards-system
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: removed
+  namespace: backyards-system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: removed
+  namespace: backyards-system
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: removed
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: removed.backyards.banzaicloud.io
+`)
+	objects := parse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kept
+  namespace: backyards-system
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: created
+  namespace: backyards-system
+`)
+
+	pruned := getPrunableResources(owned, objects)
+
+	expected := []string{"Deployment:backyards-system:removed", "ConfigMap:backyards-system:removed"}
+	if len(pruned) != len(expected) {
+		t.Fatalf("expected %d pruned objects, got %d", len(expected), len(pruned))
+	}
+	for i, obj := range pruned {
+		if obj.Hash() != expected[i] {
+			t.Errorf("expected %s at %d, got %s", expected[i], i, obj.Hash())
+		}
+	}
+}
+
+func TestPruneResourcesOfNamespace(t *testing.T) {
+	configMap := func(name, namespace string) runtime.Object {
+		cm := &corev1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    OwnershipLabels("backyards", "backyards", namespace),
+			},
+		}
+		err := patch.DefaultAnnotator.SetLastAppliedAnnotation(cm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cm
+	}
+
+	cl := unstructuredListClient{fake.NewFakeClientWithScheme(k8sclient.GetScheme(),
+		configMap("kept", "backyards-system"),
+		configMap("removed", "backyards-system"),
+		configMap("removed", "backyards-other"),
+	)}
+
+	objects, err := object.ParseK8sObjectsFromYAMLManifest(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kept
+  namespace: backyards-system
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pruned, err := PruneResources(cl, objects, GroupVersionKinds(objects), OwnershipLabels("backyards", "backyards", "backyards-system"), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(pruned) != 1 || pruned[0].Hash() != "ConfigMap:backyards-system:removed" {
+		t.Errorf("expected only ConfigMap:backyards-system:removed to be pruned, got %v", pruned)
+	}
+}
+
+// unstructuredListClient lists unstructured objects through the typed lists, since the fake client cannot list them
+type unstructuredListClient struct {
+	client.Client
+}
+
+func (c unstructuredListClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOptionFunc) error {
+	ul, ok := list.(*unstructured.UnstructuredList)
+	if !ok {
+		return c.Client.List(ctx, list, opts...)
+	}
+
+	gvk := ul.GroupVersionKind()
+	typed, err := k8sclient.GetScheme().New(gvk)
+	if err != nil {
+		return err
+	}
+	err = c.Client.List(ctx, typed, opts...)
+	if err != nil {
+		return err
+	}
+
+	items, err := meta.ExtractList(typed)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(item)
+		if err != nil {
+			return err
+		}
+		obj := unstructured.Unstructured{Object: u}
+		obj.SetGroupVersionKind(gvk.GroupVersion().WithKind(strings.TrimSuffix(gvk.Kind, "List")))
+		ul.Items = append(ul.Items, obj)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"time"
+
+	"emperror.dev/errors"
+	log "github.com/sirupsen/logrus"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+)
+
+// GetTokenForServiceAccount requests a short-lived token for the service account through the TokenRequest API, which is
+// only accepted by the given audiences. If the API is not available or the caller is not allowed to use it, the token
+// is read from the legacy token secret of the service account instead, in which case the returned expiry is zero.
+func GetTokenForServiceAccount(clientset kubernetes.Interface, client k8sclient.Client, key types.NamespacedName, audiences []string, expiration time.Duration) (string, time.Time, error) {
+	token, expiresAt, err := RequestTokenForServiceAccount(clientset, key, audiences, expiration)
+	if err == nil {
+		return token, expiresAt, nil
+	}
+
+	cause := errors.Cause(err)
+	if !k8serrors.IsNotFound(cause) && !k8serrors.IsForbidden(cause) && !k8serrors.IsMethodNotSupported(cause) {
+		return "", time.Time{}, err
+	}
+
+	log.Debugf("could not request token, falling back to the token secret of the service account: %s", err)
+
+	token, legacyErr := GetTokenForServiceAccountName(client, key)
+	if legacyErr != nil {
+		return "", time.Time{}, errors.Combine(err, legacyErr)
+	}
+
+	return token, time.Time{}, nil
+}
+
+// RequestTokenForServiceAccount requests a token for the service account through the TokenRequest API
+func RequestTokenForServiceAccount(clientset kubernetes.Interface, key types.NamespacedName, audiences []string, expiration time.Duration) (string, time.Time, error) {
+	expirationSeconds := int64(expiration.Seconds())
+
+	tr, err := clientset.CoreV1().ServiceAccounts(key.Namespace).CreateToken(key.Name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         audiences,
+			ExpirationSeconds: &expirationSeconds,
+		},
+	})
+	if err != nil {
+		return "", time.Time{}, errors.WrapIfWithDetails(err, "could not request token for service account", "name", key.String())
+	}
+
+	return tr.Status.Token, tr.Status.ExpirationTimestamp.Time, nil
+}
@@ -0,0 +1,126 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+)
+
+func TestGetTokenForServiceAccount(t *testing.T) {
+	key := types.NamespacedName{Name: "backyards", Namespace: "backyards-system"}
+	expiresAt := metav1.NewTime(time.Now().Add(10 * time.Minute).Truncate(time.Second))
+	resource := schema.GroupResource{Resource: "serviceaccounts/token"}
+
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Secrets:    []corev1.ObjectReference{{Name: "backyards-token-abcde"}},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "backyards-token-abcde", Namespace: key.Namespace},
+		Data:       map[string][]byte{corev1.ServiceAccountTokenKey: []byte("legacy-token")},
+	}
+
+	tests := []struct {
+		name          string
+		tokenRequest  func(tr *authenticationv1.TokenRequest) (*authenticationv1.TokenRequest, error)
+		objects       []runtime.Object
+		wantToken     string
+		wantExpiresAt time.Time
+		wantErr       bool
+	}{
+		{
+			name: "requests an audience bound token",
+			tokenRequest: func(tr *authenticationv1.TokenRequest) (*authenticationv1.TokenRequest, error) {
+				if len(tr.Spec.Audiences) != 1 || tr.Spec.Audiences[0] != "backyards" {
+					t.Errorf("unexpected audiences: %v", tr.Spec.Audiences)
+				}
+				tr.Status = authenticationv1.TokenRequestStatus{Token: "bound-token", ExpirationTimestamp: expiresAt}
+				return tr, nil
+			},
+			wantToken:     "bound-token",
+			wantExpiresAt: expiresAt.Time,
+		},
+		{
+			name: "falls back to the legacy secret if the API is forbidden",
+			tokenRequest: func(tr *authenticationv1.TokenRequest) (*authenticationv1.TokenRequest, error) {
+				return nil, k8serrors.NewForbidden(resource, key.Name, nil)
+			},
+			objects:   []runtime.Object{serviceAccount, secret},
+			wantToken: "legacy-token",
+		},
+		{
+			name: "fails if the legacy secret does not exist either",
+			tokenRequest: func(tr *authenticationv1.TokenRequest) (*authenticationv1.TokenRequest, error) {
+				return nil, k8serrors.NewNotFound(resource, key.Name)
+			},
+			objects: []runtime.Object{serviceAccount},
+			wantErr: true,
+		},
+		{
+			name: "does not fall back on other errors",
+			tokenRequest: func(tr *authenticationv1.TokenRequest) (*authenticationv1.TokenRequest, error) {
+				return nil, k8serrors.NewInternalError(errors.New("etcd is unavailable"))
+			},
+			objects: []runtime.Object{serviceAccount, secret},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				tr := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+				resp, err := test.tokenRequest(tr)
+				return true, resp, err
+			})
+			client := fakeclient.NewFakeClientWithScheme(k8sclient.GetScheme(), test.objects...)
+
+			token, expiresAt, err := GetTokenForServiceAccount(clientset, client, key, []string{"backyards"}, 10*time.Minute)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got token %q", token)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if token != test.wantToken {
+				t.Errorf("expected token %q, got %q", test.wantToken, token)
+			}
+			if !expiresAt.Equal(test.wantExpiresAt) {
+				t.Errorf("expected expiry %s, got %s", test.wantExpiresAt, expiresAt)
+			}
+		})
+	}
+}
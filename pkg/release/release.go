@@ -0,0 +1,338 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package release keeps records of the releases installed by the CLI, so the installed resources
+// are known when a release is upgraded, rolled back or uninstalled.
+// Every revision of a release is stored in a Secret in the namespace of the release.
+package release
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+)
+
+const (
+	StatusDeployed   = "deployed"
+	StatusSuperseded = "superseded"
+
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedBy      = "backyards-cli"
+	releaseLabel   = "backyards.banzaicloud.io/release"
+	revisionLabel  = "backyards.banzaicloud.io/revision"
+	statusLabel    = "backyards.banzaicloud.io/status"
+
+	manifestKey   = "manifest.gz"
+	valuesKey     = "values.gz"
+	userValuesKey = "user-values.gz"
+	versionKey    = "version"
+	timestampKey  = "timestamp"
+)
+
+// Release is a revision of a release installed by the CLI
+type Release struct {
+	Name      string    `json:"name"`
+	Revision  int       `json:"revision"`
+	Status    string    `json:"status"`
+	Version   string    `json:"version"`
+	Timestamp time.Time `json:"timestamp"`
+	// Manifest is the rendered YAML manifest of the release
+	Manifest string `json:"-"`
+	// Values are the chart values the manifest was rendered with in YAML format
+	Values string `json:"-"`
+	// UserValues are the values given by the user in YAML format, which are reused on upgrade
+	// on top of the chart defaults of the new version
+	UserValues string `json:"-"`
+}
+
+// NotFoundError is returned when a release or a revision of it does not exist
+type NotFoundError struct {
+	Name     string
+	Revision int
+}
+
+func (e NotFoundError) Error() string {
+	if e.Revision > 0 {
+		return fmt.Sprintf("revision %d of release %s not found", e.Revision, e.Name)
+	}
+
+	return fmt.Sprintf("release %s not found", e.Name)
+}
+
+// IsNotFound checks whether the error or its cause is a NotFoundError
+func IsNotFound(err error) bool {
+	var notFound NotFoundError
+	return errors.As(err, &notFound)
+}
+
+// Store stores the releases in Secrets in a namespace
+type Store struct {
+	client    k8sclient.Client
+	namespace string
+}
+
+func NewStore(client k8sclient.Client, namespace string) *Store {
+	return &Store{
+		client:    client,
+		namespace: namespace,
+	}
+}
+
+// History returns every revision of the release ordered by revision
+func (s *Store) History(name string) ([]*Release, error) {
+	var secrets corev1.SecretList
+	err := s.client.List(context.Background(), &secrets, client.InNamespace(s.namespace), client.MatchingLabels(map[string]string{
+		managedByLabel: managedBy,
+		releaseLabel:   name,
+	}))
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not list release secrets", "namespace", s.namespace)
+	}
+
+	releases := make([]*Release, 0, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		release, err := decode(secret)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not decode release", "secret", secret.Name)
+		}
+		releases = append(releases, release)
+	}
+
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].Revision < releases[j].Revision
+	})
+
+	return releases, nil
+}
+
+// Last returns the latest revision of the release
+func (s *Store) Last(name string) (*Release, error) {
+	releases, err := s.History(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(releases) == 0 {
+		return nil, NotFoundError{Name: name}
+	}
+
+	return releases[len(releases)-1], nil
+}
+
+// Get returns the given revision of the release
+func (s *Store) Get(name string, revision int) (*Release, error) {
+	var secret corev1.Secret
+	err := s.client.Get(context.Background(), client.ObjectKey{Name: secretName(name, revision), Namespace: s.namespace}, &secret)
+	if k8serrors.IsNotFound(err) {
+		return nil, NotFoundError{Name: name, Revision: revision}
+	}
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not get release secret", "name", name, "revision", revision)
+	}
+
+	return decode(secret)
+}
+
+// Create stores a new revision of the release as deployed and marks the previous revision as superseded
+func (s *Store) Create(release *Release) error {
+	last, err := s.Last(release.Name)
+	if err != nil && !IsNotFound(err) {
+		return err
+	}
+
+	release.Revision = 1
+	if last != nil {
+		release.Revision = last.Revision + 1
+	}
+	release.Status = StatusDeployed
+	if release.Timestamp.IsZero() {
+		release.Timestamp = time.Now()
+	}
+
+	secret, err := s.encode(release)
+	if err != nil {
+		return err
+	}
+
+	err = s.client.Create(context.Background(), secret)
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not create release secret", "name", release.Name, "revision", release.Revision)
+	}
+
+	if last != nil && last.Status != StatusSuperseded {
+		last.Status = StatusSuperseded
+		secret, err := s.encode(last)
+		if err != nil {
+			return err
+		}
+		err = s.client.Update(context.Background(), secret)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "could not update release secret", "name", last.Name, "revision", last.Revision)
+		}
+	}
+
+	return nil
+}
+
+// Delete deletes every revision of the release
+func (s *Store) Delete(name string) error {
+	releases, err := s.History(name)
+	if err != nil {
+		return err
+	}
+
+	for _, release := range releases {
+		err = s.client.Delete(context.Background(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName(release.Name, release.Revision),
+				Namespace: s.namespace,
+			},
+		})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.WrapIfWithDetails(err, "could not delete release secret", "name", release.Name, "revision", release.Revision)
+		}
+	}
+
+	return nil
+}
+
+func secretName(name string, revision int) string {
+	return fmt.Sprintf("%s.release.v%d", name, revision)
+}
+
+func (s *Store) encode(release *Release) (*corev1.Secret, error) {
+	manifest, err := compress(release.Manifest)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not compress manifest")
+	}
+
+	values, err := compress(release.Values)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not compress values")
+	}
+
+	userValues, err := compress(release.UserValues)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not compress user values")
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName(release.Name, release.Revision),
+			Namespace: s.namespace,
+			Labels: map[string]string{
+				managedByLabel: managedBy,
+				releaseLabel:   release.Name,
+				revisionLabel:  strconv.Itoa(release.Revision),
+				statusLabel:    release.Status,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			manifestKey:   manifest,
+			valuesKey:     values,
+			userValuesKey: userValues,
+			versionKey:    []byte(release.Version),
+			timestampKey:  []byte(release.Timestamp.UTC().Format(time.RFC3339)),
+		},
+	}, nil
+}
+
+func decode(secret corev1.Secret) (*Release, error) {
+	revision, err := strconv.Atoi(secret.Labels[revisionLabel])
+	if err != nil {
+		return nil, errors.WrapIf(err, "invalid revision")
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, string(secret.Data[timestampKey]))
+	if err != nil {
+		return nil, errors.WrapIf(err, "invalid timestamp")
+	}
+
+	manifest, err := decompress(secret.Data[manifestKey])
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not decompress manifest")
+	}
+
+	values, err := decompress(secret.Data[valuesKey])
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not decompress values")
+	}
+
+	// revisions recorded by earlier versions do not have user values
+	var userValues string
+	if data, ok := secret.Data[userValuesKey]; ok {
+		userValues, err = decompress(data)
+		if err != nil {
+			return nil, errors.WrapIf(err, "could not decompress user values")
+		}
+	}
+
+	return &Release{
+		Name:       secret.Labels[releaseLabel],
+		Revision:   revision,
+		Status:     secret.Labels[statusLabel],
+		Version:    string(secret.Data[versionKey]),
+		Timestamp:  timestamp,
+		Manifest:   manifest,
+		Values:     values,
+		UserValues: userValues,
+	}, nil
+}
+
+// compress gzips the data, since the manifest can be close to the size limit of Secrets
+func compress(data string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+
+	_, err := w.Write([]byte(data))
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decompress(data []byte) (string, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	decompressed, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	return string(decompressed), nil
+}
@@ -0,0 +1,96 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8sclient "github.com/banzaicloud/backyards-cli/pkg/k8s/client"
+)
+
+func TestStore(t *testing.T) {
+	store := NewStore(fake.NewFakeClientWithScheme(k8sclient.GetScheme()), "backyards-system")
+
+	_, err := store.Last("backyards")
+	if !IsNotFound(err) {
+		t.Fatalf("expected a not found error without revisions, got %v", err)
+	}
+
+	for _, manifest := range []string{"kind: ConfigMap\n", "kind: Secret\n", "kind: Service\n"} {
+		err = store.Create(&Release{
+			Name:       "backyards",
+			Version:    "1.0.0",
+			Manifest:   manifest,
+			Values:     "replicaCount: 1\n",
+			UserValues: "{}\n",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// revisions of other releases are kept apart
+	err = store.Create(&Release{Name: "other", Manifest: "kind: Pod\n"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	releases, err := store.History("backyards")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(releases) != 3 {
+		t.Fatalf("expected 3 revisions, got %d", len(releases))
+	}
+	for i, r := range releases {
+		wantStatus := StatusSuperseded
+		if i == len(releases)-1 {
+			wantStatus = StatusDeployed
+		}
+		if r.Revision != i+1 || r.Status != wantStatus {
+			t.Errorf("expected revision %d to be %s, got revision %d %s", i+1, wantStatus, r.Revision, r.Status)
+		}
+		if r.Version != "1.0.0" || r.Values != "replicaCount: 1\n" || r.UserValues != "{}\n" || r.Timestamp.IsZero() {
+			t.Errorf("unexpected revision %d: %+v", r.Revision, r)
+		}
+	}
+
+	r, err := store.Get("backyards", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Manifest != "kind: Secret\n" {
+		t.Errorf("unexpected manifest of revision 2: %q", r.Manifest)
+	}
+
+	_, err = store.Get("backyards", 4)
+	if !IsNotFound(err) {
+		t.Errorf("expected a not found error for a missing revision, got %v", err)
+	}
+
+	err = store.Delete("backyards")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = store.Last("backyards")
+	if !IsNotFound(err) {
+		t.Errorf("expected a not found error after delete, got %v", err)
+	}
+	if _, err = store.Last("other"); err != nil {
+		t.Errorf("expected the other release to be kept, got %v", err)
+	}
+}